@@ -0,0 +1,277 @@
+package gapiai
+
+/***********************************************************************************************************************
+ *
+ * Go client-side library for API.AI
+ * =================================================
+ *
+ * Copyright (C) 2017 by Slava Vasylyev
+ *
+ *
+ * *********************************************************************************************************************
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance with
+ * the License. You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ *
+ ***********************************************************************************************************************/
+
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+type (
+	//MissingParametersError is returned by Bind when required parameters are absent or empty.
+	//Names of nested parameters are joined with dots, e.g. "address.city".
+	MissingParametersError struct {
+		Names []string
+	}
+)
+
+var (
+	timeType         = reflect.TypeOf(time.Time{})
+	durationType     = reflect.TypeOf(time.Duration(0))
+	unitCurrencyType = reflect.TypeOf(UnitCurrency{})
+	parametersType   = reflect.TypeOf(Parameters{})
+)
+
+func (e *MissingParametersError) Error() string {
+	return "gapiai: missing required parameters: " + strings.Join(e.Names, ", ")
+}
+
+//BindParameters stores result parameters into the struct pointed to by v, see Parameters.Bind.
+func (result *QueryResult) BindParameters(v interface{}) error {
+	return result.Parameters.Bind(v)
+}
+
+//Bind stores parameters into the struct pointed to by v. A field is bound to the parameter named by
+//its `apiai:"name"` tag, or to the parameter matching the field name case-insensitively when there is
+//no tag. Fields tagged "-" are skipped. Values are converted by the field type: time.Time from
+//@sys.date and @sys.time, time.Duration from @sys.duration, UnitCurrency from @sys.unit-currency,
+//numbers from numeric strings, slices from lists and structs from composite entities.
+//
+//Tag option "required", as in `apiai:"city,required"`, makes absent or empty parameters reported
+//with MissingParametersError after all other fields are bound.
+func (p Parameters) Bind(v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return errors.New("gapiai: Bind requires a non-nil pointer to struct")
+	}
+	var missing []string
+	if err := p.bindStruct(rv.Elem(), "", &missing); err != nil {
+		return err
+	}
+	if len(missing) > 0 {
+		return &MissingParametersError{Names: missing}
+	}
+	return nil
+}
+
+func (p Parameters) bindStruct(rv reflect.Value, prefix string, missing *[]string) error {
+	t := rv.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+		tag := field.Tag.Get("apiai")
+		if tag == "-" {
+			continue
+		}
+		options := strings.Split(tag, ",")
+		name := options[0]
+		required := false
+		for _, option := range options[1:] {
+			if option == "required" {
+				required = true
+			}
+		}
+
+		key, ok := p.lookup(name, field.Name)
+		if !ok || isEmptyParameter(p[key]) {
+			if required {
+				*missing = append(*missing, prefix+nameOr(name, field.Name))
+			}
+			continue
+		}
+		if err := p.bindValue(rv.Field(i), key, prefix+key+".", missing); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func nameOr(name string, fieldName string) string {
+	if name != "" {
+		return name
+	}
+	return fieldName
+}
+
+//lookup finds the parameter by the tag name or, if there is no tag, by the field name.
+func (p Parameters) lookup(name string, fieldName string) (string, bool) {
+	if name != "" {
+		_, ok := p[name]
+		return name, ok
+	}
+	if _, ok := p[fieldName]; ok {
+		return fieldName, true
+	}
+	for key := range p {
+		if strings.EqualFold(key, fieldName) {
+			return key, true
+		}
+	}
+	return "", false
+}
+
+func isEmptyParameter(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
+	case string:
+		return v == ""
+	case []interface{}:
+		return len(v) == 0
+	}
+	return false
+}
+
+//bindValue converts parameter name into rv. The prefix is used to report missing nested parameters.
+func (p Parameters) bindValue(rv reflect.Value, name string, prefix string, missing *[]string) error {
+	switch rv.Type() {
+	case timeType:
+		t, err := p.GetTime(name)
+		if err != nil {
+			return err
+		}
+		rv.Set(reflect.ValueOf(t))
+		return nil
+	case durationType:
+		d, err := p.GetDuration(name)
+		if err != nil {
+			return err
+		}
+		rv.SetInt(int64(d))
+		return nil
+	case unitCurrencyType:
+		u, err := p.GetUnitCurrency(name)
+		if err != nil {
+			return err
+		}
+		rv.Set(reflect.ValueOf(u))
+		return nil
+	case parametersType:
+		obj, err := p.GetParameters(name)
+		if err != nil {
+			return err
+		}
+		rv.Set(reflect.ValueOf(obj))
+		return nil
+	}
+
+	switch rv.Kind() {
+	case reflect.String:
+		s, err := p.GetString(name)
+		if err != nil {
+			return err
+		}
+		rv.SetString(s)
+	case reflect.Bool:
+		switch v := p[name].(type) {
+		case bool:
+			rv.SetBool(v)
+		case string:
+			switch strings.ToLower(v) {
+			case "true":
+				rv.SetBool(true)
+			case "false":
+				rv.SetBool(false)
+			default:
+				return typeError(name, "boolean", v)
+			}
+		default:
+			return typeError(name, "boolean", v)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := p.GetInt(name)
+		if err != nil {
+			return err
+		}
+		if rv.OverflowInt(int64(n)) {
+			return fmt.Errorf("%w: parameter %q value %d overflows %s", ErrParameterType, name, n, rv.Type())
+		}
+		rv.SetInt(int64(n))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := p.GetInt(name)
+		if err != nil {
+			return err
+		}
+		if n < 0 || rv.OverflowUint(uint64(n)) {
+			return fmt.Errorf("%w: parameter %q value %d overflows %s", ErrParameterType, name, n, rv.Type())
+		}
+		rv.SetUint(uint64(n))
+	case reflect.Float32, reflect.Float64:
+		f, err := p.GetFloat(name)
+		if err != nil {
+			return err
+		}
+		rv.SetFloat(f)
+	case reflect.Ptr:
+		elem := reflect.New(rv.Type().Elem())
+		if err := p.bindValue(elem.Elem(), name, prefix, missing); err != nil {
+			return err
+		}
+		rv.Set(elem)
+	case reflect.Slice:
+		items, ok := p[name].([]interface{})
+		if !ok {
+			items = []interface{}{p[name]}
+		}
+		slice := reflect.MakeSlice(rv.Type(), len(items), len(items))
+		for i, item := range items {
+			//each item is bound as a parameter of the same name to reuse conversions
+			if err := (Parameters{name: item}).bindValue(slice.Index(i), name, prefix, missing); err != nil {
+				return err
+			}
+		}
+		rv.Set(slice)
+	case reflect.Struct:
+		obj, err := p.GetParameters(name)
+		if err != nil {
+			return err
+		}
+		return obj.bindStruct(rv, prefix, missing)
+	case reflect.Interface:
+		value := reflect.ValueOf(p[name])
+		if !value.IsValid() {
+			return nil
+		}
+		if value.Type().AssignableTo(rv.Type()) {
+			rv.Set(value)
+			return nil
+		}
+		return typeError(name, rv.Type().String(), p[name])
+	default:
+		//anything else is left to encoding/json
+		data, err := json.Marshal(p[name])
+		if err != nil {
+			return err
+		}
+		if err := json.Unmarshal(data, rv.Addr().Interface()); err != nil {
+			return fmt.Errorf("%w: parameter %q: %v", ErrParameterType, name, err)
+		}
+	}
+	return nil
+}
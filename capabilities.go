@@ -0,0 +1,106 @@
+package gapiai
+
+/***********************************************************************************************************************
+ *
+ * Go client-side library for API.AI
+ * =================================================
+ *
+ * Copyright (C) 2017 by Slava Vasylyev
+ *
+ *
+ * *********************************************************************************************************************
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance with
+ * the License. You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ *
+ ***********************************************************************************************************************/
+
+
+import (
+	"fmt"
+	"strings"
+)
+
+//Capability is a feature of the API that may be available only for some languages.
+//Capabilities are bit flags and can be combined.
+type Capability int
+
+const (
+	TextQuery Capability = 1 << iota
+	TTS
+	SpeechRecognition
+	SmallTalk
+)
+
+var capabilityNames = []struct {
+	capability Capability
+	name       string
+}{
+	{TextQuery, "text query"},
+	{TTS, "TTS"},
+	{SpeechRecognition, "speech recognition"},
+	{SmallTalk, "small talk"},
+}
+
+func (c Capability) String() string {
+	var names []string
+	for _, n := range capabilityNames {
+		if c&n.capability != 0 {
+			names = append(names, n.name)
+		}
+	}
+	if len(names) == 0 {
+		return fmt.Sprintf("Capability(%d)", int(c))
+	}
+	return strings.Join(names, ", ")
+}
+
+//LanguageCapabilities lists capabilities of every supported language. It may be changed at start up,
+//before endpoints are used, when the API adds features for a language.
+var LanguageCapabilities = map[SupportedLang]Capability{
+	English:          TextQuery | TTS | SpeechRecognition | SmallTalk,
+	EnglishUS:        TextQuery | TTS | SpeechRecognition | SmallTalk,
+	Russian:          TextQuery | SpeechRecognition | SmallTalk,
+	RussianRU:        TextQuery | SpeechRecognition | SmallTalk,
+	German:           TextQuery | SpeechRecognition | SmallTalk,
+	Portuguese:       TextQuery | SpeechRecognition | SmallTalk,
+	PortugueseBrazil: TextQuery | SpeechRecognition | SmallTalk,
+	Spanish:          TextQuery | SpeechRecognition | SmallTalk,
+	French:           TextQuery | SpeechRecognition | SmallTalk,
+	Italian:          TextQuery | SpeechRecognition | SmallTalk,
+	Japanese:         TextQuery | SpeechRecognition,
+	Korean:           TextQuery | SpeechRecognition,
+	ChineseChina:     TextQuery | SpeechRecognition | SmallTalk,
+	ChineseHongKong:  TextQuery | SpeechRecognition,
+	ChineseTaiwan:    TextQuery | SpeechRecognition,
+}
+
+//UnsupportedCapabilityError is returned when a request needs a capability its language does not have.
+type UnsupportedCapabilityError struct {
+	Lang       SupportedLang
+	Capability Capability
+}
+
+func (e *UnsupportedCapabilityError) Error() string {
+	return fmt.Sprintf("gapiai: %s is not supported for language %q", e.Capability, e.Lang)
+}
+
+//Supports reports whether the language has all capabilities of c.
+func (lang SupportedLang) Supports(c Capability) bool {
+	return LanguageCapabilities[lang]&c == c
+}
+
+//checkCapability returns UnsupportedCapabilityError when lang lacks c. Empty language is left for
+//the API to default.
+func checkCapability(lang SupportedLang, c Capability) error {
+	if lang == "" || lang.Supports(c) {
+		return nil
+	}
+	return &UnsupportedCapabilityError{Lang: lang, Capability: c}
+}
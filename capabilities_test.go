@@ -0,0 +1,85 @@
+package gapiai_test
+
+/***********************************************************************************************************************
+ *
+ * Go client-side library for API.AI
+ * =================================================
+ *
+ * Copyright (C) 2017 by Slava Vasylyev
+ *
+ *
+ * *********************************************************************************************************************
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance with
+ * the License. You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ *
+ ***********************************************************************************************************************/
+
+
+import (
+	. "github.com/slavaVA/go-api.ai"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"bytes"
+	"errors"
+	"github.com/onsi/gomega/ghttp"
+	"io"
+)
+
+var _ = Describe("Capabilities", func() {
+	var server *ghttp.Server
+	var apiConfig *ApiConfig
+
+	BeforeEach(func() {
+		server = ghttp.NewServer()
+		apiConfig = &ApiConfig{AccessToken: "123456789"}
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("Should report capabilities of languages", func() {
+		Ω(English.Supports(TTS | SpeechRecognition)).Should(BeTrue())
+		Ω(Japanese.Supports(TextQuery)).Should(BeTrue())
+		Ω(Japanese.Supports(TextQuery | SmallTalk)).Should(BeFalse())
+		Ω(SupportedLang("xx").Supports(TextQuery)).Should(BeFalse())
+		Ω((TextQuery | TTS).String()).Should(Equal("text query, TTS"))
+	})
+
+	It("Should fail TTS for language without TTS", func() {
+		apiConfig.Lang = Japanese
+		ttsService := NewTtsAPIEndpoint(server.URL()+"/v1/", CurrentAPIVersion, apiConfig)
+		err := ttsService.DoTts("Hello", func(r io.Reader) error { return nil })
+
+		var capabilityErr *UnsupportedCapabilityError
+		Ω(errors.As(err, &capabilityErr)).Should(BeTrue())
+		Ω(capabilityErr.Lang).Should(Equal(Japanese))
+		Ω(capabilityErr.Capability).Should(Equal(TTS))
+		Ω(server.ReceivedRequests()).Should(HaveLen(0))
+	})
+
+	It("Should fail voice query for language without speech recognition", func() {
+		saved := LanguageCapabilities[Korean]
+		LanguageCapabilities[Korean] = TextQuery
+		defer func() { LanguageCapabilities[Korean] = saved }()
+
+		apiConfig.Lang = English
+		apiService := NewQueryAPIEndpoint(server.URL()+"/v1/", CurrentAPIVersion, apiConfig)
+		q := Query{SessionID: "111", Lang: string(Korean)}
+		_, err := apiService.DoVoiceQuery(q, bytes.NewReader(makeWave(16000, 1, 100)))
+
+		var capabilityErr *UnsupportedCapabilityError
+		Ω(errors.As(err, &capabilityErr)).Should(BeTrue())
+		Ω(capabilityErr.Capability).Should(Equal(SpeechRecognition))
+		Ω(err.Error()).Should(Equal(`gapiai: speech recognition is not supported for language "ko"`))
+		Ω(server.ReceivedRequests()).Should(HaveLen(0))
+	})
+})
@@ -0,0 +1,116 @@
+package gapiai
+
+/***********************************************************************************************************************
+ *
+ * Go client-side library for API.AI
+ * =================================================
+ *
+ * Copyright (C) 2017 by Slava Vasylyev
+ *
+ *
+ * *********************************************************************************************************************
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance with
+ * the License. You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ *
+ ***********************************************************************************************************************/
+
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+)
+
+type (
+	ContextsService struct {
+		ApiService
+		url     string
+		version string
+	}
+)
+
+func NewContextsAPIEndpoint(url string, version string, cfg *ApiConfig) *ContextsService {
+	svc := &ContextsService{
+		ApiService: ApiService{
+			logger: nil,
+			Config: cfg,
+		},
+		url:     fmt.Sprint(url, "contexts"),
+		version: version,
+	}
+	return svc
+}
+
+func DefaultContextsAPIEndpoint(cfg *ApiConfig) *ContextsService {
+	return NewContextsAPIEndpoint(apiAiURL, CurrentAPIVersion, cfg)
+}
+
+func (service *ContextsService) contextsURL(sessionID string, path ...string) string {
+	return endpointURL(service.url, service.version, url.Values{"sessionId": {sessionID}}, path...)
+}
+
+//List returns all active contexts of the session.
+func (service *ContextsService) List(sessionID string) ([]DialogContext, error) {
+	return service.ListWithContext(context.Background(), sessionID)
+}
+
+func (service *ContextsService) ListWithContext(ctx context.Context, sessionID string) ([]DialogContext, error) {
+	var contexts []DialogContext
+	if err := service.doJSON(ctx, "GET", service.contextsURL(sessionID), nil, &contexts); err != nil {
+		return nil, err
+	}
+	return contexts, nil
+}
+
+//Get returns the named context of the session.
+func (service *ContextsService) Get(sessionID string, name string) (*DialogContext, error) {
+	return service.GetWithContext(context.Background(), sessionID, name)
+}
+
+func (service *ContextsService) GetWithContext(ctx context.Context, sessionID string, name string) (*DialogContext, error) {
+	dialogContext := &DialogContext{}
+	if err := service.doJSON(ctx, "GET", service.contextsURL(sessionID, name), nil, dialogContext); err != nil {
+		return nil, err
+	}
+	return dialogContext, nil
+}
+
+//Add adds contexts to the session and returns names of the added contexts.
+func (service *ContextsService) Add(sessionID string, contexts ...DialogContext) ([]string, error) {
+	return service.AddWithContext(context.Background(), sessionID, contexts...)
+}
+
+func (service *ContextsService) AddWithContext(ctx context.Context, sessionID string, contexts ...DialogContext) ([]string, error) {
+	var response struct {
+		Names []string `json:"names"`
+	}
+	if err := service.doJSON(ctx, "POST", service.contextsURL(sessionID), contexts, &response); err != nil {
+		return nil, err
+	}
+	return response.Names, nil
+}
+
+//Delete removes the named context from the session.
+func (service *ContextsService) Delete(sessionID string, name string) error {
+	return service.DeleteWithContext(context.Background(), sessionID, name)
+}
+
+func (service *ContextsService) DeleteWithContext(ctx context.Context, sessionID string, name string) error {
+	return service.doJSON(ctx, "DELETE", service.contextsURL(sessionID, name), nil, nil)
+}
+
+//DeleteAll removes all contexts from the session.
+func (service *ContextsService) DeleteAll(sessionID string) error {
+	return service.DeleteAllWithContext(context.Background(), sessionID)
+}
+
+func (service *ContextsService) DeleteAllWithContext(ctx context.Context, sessionID string) error {
+	return service.doJSON(ctx, "DELETE", service.contextsURL(sessionID), nil, nil)
+}
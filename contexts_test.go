@@ -0,0 +1,131 @@
+package gapiai_test
+
+/***********************************************************************************************************************
+ *
+ * Go client-side library for API.AI
+ * =================================================
+ *
+ * Copyright (C) 2017 by Slava Vasylyev
+ *
+ *
+ * *********************************************************************************************************************
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance with
+ * the License. You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ *
+ ***********************************************************************************************************************/
+
+
+import (
+	. "github.com/slavaVA/go-api.ai"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/ghttp"
+	"net/http"
+)
+
+var _ = Describe("Contexts", func() {
+	var server *ghttp.Server
+	var apiService *ContextsService
+	testToken := "123456789"
+	authHeader := ghttp.VerifyHeader(http.Header{
+		"Authorization": []string{"Bearer " + testToken},
+	})
+
+	BeforeEach(func() {
+		server = ghttp.NewServer()
+		apiConfig := &ApiConfig{
+			AccessToken: testToken,
+			Lang:        English,
+		}
+		apiService = NewContextsAPIEndpoint(server.URL()+"/v1/", CurrentAPIVersion, apiConfig)
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("Should list session contexts", func() {
+		server.AppendHandlers(
+			ghttp.CombineHandlers(
+				ghttp.VerifyRequest("GET", "/v1/contexts", "sessionId=111&v=20150910"),
+				authHeader,
+				ghttp.RespondWith(http.StatusOK, `[
+					{"name": "weather", "parameters": {"city": "Kiev"}, "lifespan": 4},
+					{"name": "music", "parameters": {}, "lifespan": 1}
+				]`),
+			),
+		)
+		contexts, err := apiService.List("111")
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(contexts).Should(HaveLen(2))
+		Ω(contexts[0].Name).Should(Equal("weather"))
+		Ω(contexts[0].Parameters["city"]).Should(Equal("Kiev"))
+		Ω(contexts[0].Lifespan).Should(Equal(4))
+	})
+
+	It("Should get named context", func() {
+		server.AppendHandlers(
+			ghttp.CombineHandlers(
+				ghttp.VerifyRequest("GET", "/v1/contexts/weather", "sessionId=111&v=20150910"),
+				authHeader,
+				ghttp.RespondWith(http.StatusOK, `{"name": "weather", "parameters": {"city": "Kiev"}, "lifespan": 4}`),
+			),
+		)
+		dialogContext, err := apiService.Get("111", "weather")
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(dialogContext.Name).Should(Equal("weather"))
+	})
+
+	It("Should report missing context", func() {
+		server.AppendHandlers(
+			ghttp.RespondWith(http.StatusNotFound, `{"status": {"code": 404, "errorType": "not_found"}}`),
+		)
+		dialogContext, err := apiService.Get("111", "weather")
+		Ω(dialogContext).Should(BeNil())
+		Ω(IsNotFound(err)).Should(BeTrue())
+	})
+
+	It("Should add contexts", func() {
+		server.AppendHandlers(
+			ghttp.CombineHandlers(
+				ghttp.VerifyRequest("POST", "/v1/contexts", "sessionId=111&v=20150910"),
+				authHeader,
+				ghttp.VerifyJSON(`[{"name": "weather", "parameters": {"city": "Kiev"}, "lifespan": 2}]`),
+				ghttp.RespondWith(http.StatusOK, `{"names": ["weather"], "status": {"code": 200, "errorType": "success"}}`),
+			),
+		)
+		names, err := apiService.Add("111", DialogContext{
+			Name:       "weather",
+			Parameters: map[string]interface{}{"city": "Kiev"},
+			Lifespan:   2,
+		})
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(names).Should(Equal([]string{"weather"}))
+	})
+
+	It("Should delete contexts", func() {
+		server.AppendHandlers(
+			ghttp.CombineHandlers(
+				ghttp.VerifyRequest("DELETE", "/v1/contexts/weather", "sessionId=111&v=20150910"),
+				authHeader,
+				ghttp.RespondWith(http.StatusOK, `{"status": {"code": 200, "errorType": "success"}}`),
+			),
+			ghttp.CombineHandlers(
+				ghttp.VerifyRequest("DELETE", "/v1/contexts", "sessionId=111&v=20150910"),
+				authHeader,
+				ghttp.RespondWith(http.StatusOK, `{"status": {"code": 200, "errorType": "success"}}`),
+			),
+		)
+		Ω(apiService.Delete("111", "weather")).Should(Succeed())
+		Ω(apiService.DeleteAll("111")).Should(Succeed())
+		Ω(server.ReceivedRequests()).Should(HaveLen(2))
+	})
+})
@@ -0,0 +1,143 @@
+package gapiai
+
+/***********************************************************************************************************************
+ *
+ * Go client-side library for API.AI
+ * =================================================
+ *
+ * Copyright (C) 2017 by Slava Vasylyev
+ *
+ *
+ * *********************************************************************************************************************
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance with
+ * the License. You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ *
+ ***********************************************************************************************************************/
+
+
+import (
+	"context"
+	"fmt"
+)
+
+type (
+	EntitiesService struct {
+		ApiService
+		url     string
+		version string
+	}
+)
+
+func NewEntitiesAPIEndpoint(url string, version string, cfg *ApiConfig) *EntitiesService {
+	svc := &EntitiesService{
+		ApiService: ApiService{
+			logger:    nil,
+			Config:    cfg,
+			developer: true,
+		},
+		url:     fmt.Sprint(url, "entities"),
+		version: version,
+	}
+	return svc
+}
+
+func DefaultEntitiesAPIEndpoint(cfg *ApiConfig) *EntitiesService {
+	return NewEntitiesAPIEndpoint(apiAiURL, CurrentAPIVersion, cfg)
+}
+
+func (service *EntitiesService) entitiesURL(path ...string) string {
+	return endpointURL(service.url, service.version, nil, path...)
+}
+
+//List returns descriptions of all agent entities.
+func (service *EntitiesService) List() ([]EntityDescription, error) {
+	return service.ListContext(context.Background())
+}
+
+func (service *EntitiesService) ListContext(ctx context.Context) ([]EntityDescription, error) {
+	var entities []EntityDescription
+	if err := service.doJSON(ctx, "GET", service.entitiesURL(), nil, &entities); err != nil {
+		return nil, err
+	}
+	return entities, nil
+}
+
+//Get returns the entity with its entries.
+func (service *EntitiesService) Get(eid string) (*Entity, error) {
+	return service.GetContext(context.Background(), eid)
+}
+
+func (service *EntitiesService) GetContext(ctx context.Context, eid string) (*Entity, error) {
+	entity := &Entity{}
+	if err := service.doJSON(ctx, "GET", service.entitiesURL(eid), nil, entity); err != nil {
+		return nil, err
+	}
+	return entity, nil
+}
+
+//Create creates the entity and returns its ID.
+func (service *EntitiesService) Create(entity Entity) (string, error) {
+	return service.CreateContext(context.Background(), entity)
+}
+
+func (service *EntitiesService) CreateContext(ctx context.Context, entity Entity) (string, error) {
+	var response struct {
+		ID string `json:"id"`
+	}
+	if err := service.doJSON(ctx, "POST", service.entitiesURL(), entity, &response); err != nil {
+		return "", err
+	}
+	return response.ID, nil
+}
+
+//Update replaces the entity including all its entries.
+func (service *EntitiesService) Update(eid string, entity Entity) error {
+	return service.UpdateContext(context.Background(), eid, entity)
+}
+
+func (service *EntitiesService) UpdateContext(ctx context.Context, eid string, entity Entity) error {
+	return service.doJSON(ctx, "PUT", service.entitiesURL(eid), entity, nil)
+}
+
+//Delete removes the entity.
+func (service *EntitiesService) Delete(eid string) error {
+	return service.DeleteContext(context.Background(), eid)
+}
+
+func (service *EntitiesService) DeleteContext(ctx context.Context, eid string) error {
+	return service.doJSON(ctx, "DELETE", service.entitiesURL(eid), nil, nil)
+}
+
+//AddEntries adds entries to the entity.
+func (service *EntitiesService) AddEntries(eid string, entries ...EntityEntry) error {
+	return service.AddEntriesContext(context.Background(), eid, entries...)
+}
+
+func (service *EntitiesService) AddEntriesContext(ctx context.Context, eid string, entries ...EntityEntry) error {
+	return service.doJSON(ctx, "POST", service.entitiesURL(eid, "entries"), entries, nil)
+}
+
+//UpdateEntries replaces synonyms of the entity entries with the same values.
+func (service *EntitiesService) UpdateEntries(eid string, entries ...EntityEntry) error {
+	return service.UpdateEntriesContext(context.Background(), eid, entries...)
+}
+
+func (service *EntitiesService) UpdateEntriesContext(ctx context.Context, eid string, entries ...EntityEntry) error {
+	return service.doJSON(ctx, "PUT", service.entitiesURL(eid, "entries"), entries, nil)
+}
+
+//DeleteEntries removes entries with the given reference values from the entity.
+func (service *EntitiesService) DeleteEntries(eid string, values ...string) error {
+	return service.DeleteEntriesContext(context.Background(), eid, values...)
+}
+
+func (service *EntitiesService) DeleteEntriesContext(ctx context.Context, eid string, values ...string) error {
+	return service.doJSON(ctx, "DELETE", service.entitiesURL(eid, "entries"), values, nil)
+}
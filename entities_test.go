@@ -0,0 +1,145 @@
+package gapiai_test
+
+/***********************************************************************************************************************
+ *
+ * Go client-side library for API.AI
+ * =================================================
+ *
+ * Copyright (C) 2017 by Slava Vasylyev
+ *
+ *
+ * *********************************************************************************************************************
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance with
+ * the License. You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ *
+ ***********************************************************************************************************************/
+
+
+import (
+	. "github.com/slavaVA/go-api.ai"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/ghttp"
+	"net/http"
+)
+
+var _ = Describe("Entities", func() {
+	var server *ghttp.Server
+	var apiService *EntitiesService
+	developerToken := "developer"
+	success := `{"status": {"code": 200, "errorType": "success"}}`
+	developerHeader := ghttp.VerifyHeader(http.Header{
+		"Authorization": []string{"Bearer " + developerToken},
+	})
+
+	BeforeEach(func() {
+		server = ghttp.NewServer()
+		apiConfig := &ApiConfig{
+			AccessToken:          "client",
+			DeveloperAccessToken: developerToken,
+			Lang:                 English,
+		}
+		apiService = NewEntitiesAPIEndpoint(server.URL()+"/v1/", CurrentAPIVersion, apiConfig)
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("Should list entities with developer token", func() {
+		server.AppendHandlers(
+			ghttp.CombineHandlers(
+				ghttp.VerifyRequest("GET", "/v1/entities", "v=20150910"),
+				developerHeader,
+				ghttp.RespondWith(http.StatusOK, `[
+					{"id": "e1", "name": "store", "count": 2, "preview": "Central <= Central, Downtown"}
+				]`),
+			),
+		)
+		entities, err := apiService.List()
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(entities).Should(Equal([]EntityDescription{
+			{ID: "e1", Name: "store", Count: 2, Preview: "Central <= Central, Downtown"},
+		}))
+	})
+
+	It("Should get, create, update and delete entity", func() {
+		store := Entity{
+			Name:    "store",
+			Entries: []EntityEntry{{Value: "Central", Synonyms: []string{"Central", "Downtown"}}},
+		}
+		storeJSON := `{
+			"name": "store",
+			"entries": [{"value": "Central", "synonyms": ["Central", "Downtown"]}],
+			"extend": false,
+			"isEnum": false
+		}`
+		server.AppendHandlers(
+			ghttp.CombineHandlers(
+				ghttp.VerifyRequest("POST", "/v1/entities", "v=20150910"),
+				developerHeader,
+				ghttp.VerifyJSON(storeJSON),
+				ghttp.RespondWith(http.StatusOK, `{"id": "e1", "status": {"code": 200, "errorType": "success"}}`),
+			),
+			ghttp.CombineHandlers(
+				ghttp.VerifyRequest("GET", "/v1/entities/e1", "v=20150910"),
+				ghttp.RespondWith(http.StatusOK, `{"id": "e1", "name": "store", "entries": [{"value": "Central", "synonyms": ["Central", "Downtown"]}]}`),
+			),
+			ghttp.CombineHandlers(
+				ghttp.VerifyRequest("PUT", "/v1/entities/e1", "v=20150910"),
+				ghttp.VerifyJSON(storeJSON),
+				ghttp.RespondWith(http.StatusOK, success),
+			),
+			ghttp.CombineHandlers(
+				ghttp.VerifyRequest("DELETE", "/v1/entities/e1", "v=20150910"),
+				ghttp.RespondWith(http.StatusOK, success),
+			),
+		)
+		id, err := apiService.Create(store)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(id).Should(Equal("e1"))
+
+		entity, err := apiService.Get(id)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(entity.ID).Should(Equal("e1"))
+		Ω(entity.Entries).Should(Equal(store.Entries))
+
+		Ω(apiService.Update(id, store)).Should(Succeed())
+		Ω(apiService.Delete(id)).Should(Succeed())
+		Ω(server.ReceivedRequests()).Should(HaveLen(4))
+	})
+
+	It("Should manage entity entries", func() {
+		entry := EntityEntry{Value: "Central", Synonyms: []string{"Central", "Downtown"}}
+		entryJSON := `[{"value": "Central", "synonyms": ["Central", "Downtown"]}]`
+		server.AppendHandlers(
+			ghttp.CombineHandlers(
+				ghttp.VerifyRequest("POST", "/v1/entities/store/entries", "v=20150910"),
+				ghttp.VerifyJSON(entryJSON),
+				ghttp.RespondWith(http.StatusOK, success),
+			),
+			ghttp.CombineHandlers(
+				ghttp.VerifyRequest("PUT", "/v1/entities/store/entries", "v=20150910"),
+				ghttp.VerifyJSON(entryJSON),
+				ghttp.RespondWith(http.StatusOK, success),
+			),
+			ghttp.CombineHandlers(
+				ghttp.VerifyRequest("DELETE", "/v1/entities/store/entries", "v=20150910"),
+				ghttp.VerifyJSON(`["Central"]`),
+				ghttp.RespondWith(http.StatusOK, success),
+			),
+		)
+		Ω(apiService.AddEntries("store", entry)).Should(Succeed())
+		Ω(apiService.UpdateEntries("store", entry)).Should(Succeed())
+		Ω(apiService.DeleteEntries("store", "Central")).Should(Succeed())
+		Ω(server.ReceivedRequests()).Should(HaveLen(3))
+	})
+})
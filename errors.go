@@ -0,0 +1,113 @@
+package gapiai
+
+/***********************************************************************************************************************
+ *
+ * Go client-side library for API.AI
+ * =================================================
+ *
+ * Copyright (C) 2017 by Slava Vasylyev
+ *
+ *
+ * *********************************************************************************************************************
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance with
+ * the License. You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ *
+ ***********************************************************************************************************************/
+
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+type (
+	//APIError is returned when API.AI responds with an unsuccessful HTTP status or status object.
+	//Use errors.As to get it from the returned error, or IsUnauthorized, IsRateLimited and IsNotFound
+	//to branch on the most common failures.
+	APIError struct {
+		StatusObject
+		//HTTPStatus is the status code of the HTTP response.
+		HTTPStatus int
+	}
+)
+
+var (
+	ErrUnauthorized = errors.New("gapiai: unauthorized")
+	//ErrRateLimited matches APIError of API.AI responding with 429 Too Many Requests. It is not
+	//ErrRateLimitExceeded, which is returned by the client side RateLimiter without sending a request.
+	ErrRateLimited = errors.New("gapiai: rate limited")
+	ErrNotFound    = errors.New("gapiai: not found")
+)
+
+func newAPIError(resp *http.Response, body []byte) *APIError {
+	apiErr := &APIError{
+		HTTPStatus: resp.StatusCode,
+	}
+	var r struct {
+		Status *StatusObject `json:"status"`
+	}
+	if err := json.Unmarshal(body, &r); err == nil && r.Status != nil {
+		apiErr.StatusObject = *r.Status
+	}
+	if apiErr.Code == 0 {
+		apiErr.Code = resp.StatusCode
+	}
+	if apiErr.ErrorDetails == "" && apiErr.ErrorType == "" {
+		apiErr.ErrorDetails = string(body)
+	}
+	return apiErr
+}
+
+func (e *APIError) Error() string {
+	msg := fmt.Sprintf("gapiai: http status %d, code %d", e.HTTPStatus, e.Code)
+	if e.ErrorType != "" {
+		msg += ", " + e.ErrorType
+	}
+	if e.ErrorID != "" {
+		msg += ", errorId " + e.ErrorID
+	}
+	if e.ErrorDetails != "" {
+		msg += ": " + e.ErrorDetails
+	}
+	return msg
+}
+
+//Is reports whether e matches one of ErrUnauthorized, ErrRateLimited or ErrNotFound.
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrUnauthorized:
+		return e.hasCode(http.StatusUnauthorized)
+	case ErrRateLimited:
+		return e.hasCode(http.StatusTooManyRequests)
+	case ErrNotFound:
+		return e.hasCode(http.StatusNotFound)
+	}
+	return false
+}
+
+func (e *APIError) hasCode(code int) bool {
+	return e.HTTPStatus == code || e.Code == code
+}
+
+func IsUnauthorized(err error) bool {
+	return errors.Is(err, ErrUnauthorized)
+}
+
+//IsRateLimited reports whether API.AI rejected the request with 429. It is false for ErrRateLimitExceeded
+//of the client side RateLimiter.
+func IsRateLimited(err error) bool {
+	return errors.Is(err, ErrRateLimited)
+}
+
+func IsNotFound(err error) bool {
+	return errors.Is(err, ErrNotFound)
+}
@@ -0,0 +1,115 @@
+package gapiai
+
+/***********************************************************************************************************************
+ *
+ * Go client-side library for API.AI
+ * =================================================
+ *
+ * Copyright (C) 2017 by Slava Vasylyev
+ *
+ *
+ * *********************************************************************************************************************
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance with
+ * the License. You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ *
+ ***********************************************************************************************************************/
+
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+//ErrInvalidQuery is returned when the query can not be sent, e.g. it has both query text and an event.
+var ErrInvalidQuery = errors.New("gapiai: invalid query")
+
+func (service *QueryService) EventRequest(sessionID string, name string, data interface{}) (*QueryResponse, error) {
+	return service.EventRequestContext(context.Background(), sessionID, name, data)
+}
+
+//EventRequestContext triggers the intent bound to the event name. Data is converted with NewEvent.
+func (service *QueryService) EventRequestContext(ctx context.Context, sessionID string, name string, data interface{}) (*QueryResponse, error) {
+	event, err := NewEvent(name, data)
+	if err != nil {
+		return nil, err
+	}
+	q := Query{
+		Event:     event,
+		SessionID: sessionID,
+	}
+	return service.DoQueryContext(ctx, q)
+}
+
+//NewEvent creates the event with data taken from a map or a struct. Data is encoded with encoding/json
+//and every field becomes a string value, as API.AI expects: strings are kept as is, numbers and booleans
+//are formatted and nested objects or arrays are passed as JSON text. Null fields are omitted.
+func NewEvent(name string, data interface{}) (*Event, error) {
+	if name == "" {
+		return nil, fmt.Errorf("%w: event name is empty", ErrInvalidQuery)
+	}
+	event := &Event{Name: name}
+	switch d := data.(type) {
+	case nil:
+		return event, nil
+	case map[string]string:
+		event.Data = d
+		return event, nil
+	}
+
+	b, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(b, &fields); err != nil || fields == nil {
+		return nil, fmt.Errorf("%w: event data must be a map or a struct, got %T", ErrInvalidQuery, data)
+	}
+
+	event.Data = make(map[string]string, len(fields))
+	for name, raw := range fields {
+		var value string
+		switch {
+		case bytes.Equal(raw, []byte("null")):
+			continue
+		case raw[0] == '"':
+			if err := json.Unmarshal(raw, &value); err != nil {
+				return nil, err
+			}
+		default:
+			value = string(raw)
+		}
+		event.Data[name] = value
+	}
+	return event, nil
+}
+
+//validate checks that the query has either query text or an event with a name, and that confidence
+//values, when set, are valid and match query texts.
+func (q *Query) validate() error {
+	switch {
+	case len(q.Query) > 0 && q.Event != nil:
+		return fmt.Errorf("%w: both query text and event are set", ErrInvalidQuery)
+	case len(q.Query) == 0 && q.Event == nil:
+		return fmt.Errorf("%w: neither query text nor event is set", ErrInvalidQuery)
+	case q.Event != nil && q.Event.Name == "":
+		return fmt.Errorf("%w: event name is empty", ErrInvalidQuery)
+	case len(q.Confidence) > 0 && len(q.Confidence) != len(q.Query):
+		return fmt.Errorf("%w: %d confidence values for %d query texts", ErrInvalidQuery, len(q.Confidence), len(q.Query))
+	}
+	for _, c := range q.Confidence {
+		if !validConfidence(c) {
+			return fmt.Errorf("%w: confidence %v is not in [0, 1]", ErrInvalidQuery, c)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+package gapiai_test
+
+/***********************************************************************************************************************
+ *
+ * Go client-side library for API.AI
+ * =================================================
+ *
+ * Copyright (C) 2017 by Slava Vasylyev
+ *
+ *
+ * *********************************************************************************************************************
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance with
+ * the License. You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ *
+ ***********************************************************************************************************************/
+
+
+import (
+	. "github.com/slavaVA/go-api.ai"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"errors"
+	"github.com/onsi/gomega/ghttp"
+	"net/http"
+)
+
+var _ = Describe("Event", func() {
+	var server *ghttp.Server
+	var apiService *QueryService
+
+	BeforeEach(func() {
+		server = ghttp.NewServer()
+		apiConfig := &ApiConfig{
+			AccessToken: "123456789",
+			Lang:        English,
+		}
+		apiService = NewQueryAPIEndpoint(server.URL()+"/v1/", CurrentAPIVersion, apiConfig)
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("Should send event request with typed data", func() {
+		server.AppendHandlers(
+			ghttp.CombineHandlers(
+				ghttp.VerifyRequest("POST", "/v1/query", "v=20150910"),
+				ghttp.VerifyJSON(`{
+					"event": {
+						"name": "order_ready",
+						"data": {"name": "Sam", "count": "3", "price": "9.5", "paid": "true", "items": "[\"tea\",\"cake\"]"}
+					},
+					"lang": "en",
+					"sessionId": "111"
+				}`),
+				ghttp.RespondWith(http.StatusOK, `{"result": {"action": "order.ready"}, "status": {"code": 200}, "sessionId": "111"}`),
+			),
+		)
+		data := struct {
+			Name  string   `json:"name"`
+			Count int      `json:"count"`
+			Price float64  `json:"price"`
+			Paid  bool     `json:"paid"`
+			Items []string `json:"items"`
+			Note  *string  `json:"note"`
+		}{"Sam", 3, 9.5, true, []string{"tea", "cake"}, nil}
+
+		response, err := apiService.EventRequest("111", "order_ready", data)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(response.Result.Action).Should(Equal("order.ready"))
+	})
+
+	It("Should send event without data", func() {
+		server.AppendHandlers(
+			ghttp.CombineHandlers(
+				ghttp.VerifyJSON(`{"event": {"name": "WELCOME"}, "lang": "en", "sessionId": "111"}`),
+				ghttp.RespondWith(http.StatusOK, `{"status": {"code": 200}, "sessionId": "111"}`),
+			),
+		)
+		_, err := apiService.EventRequest("111", "WELCOME", nil)
+		Ω(err).ShouldNot(HaveOccurred())
+	})
+
+	It("Should convert map data", func() {
+		event, err := NewEvent("WELCOME", map[string]interface{}{"age": 30, "vip": false})
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(event.Data).Should(Equal(map[string]string{"age": "30", "vip": "false"}))
+	})
+
+	It("Should reject invalid events", func() {
+		_, err := NewEvent("", nil)
+		Ω(errors.Is(err, ErrInvalidQuery)).Should(BeTrue())
+		_, err = NewEvent("WELCOME", []string{"Sam"})
+		Ω(errors.Is(err, ErrInvalidQuery)).Should(BeTrue())
+	})
+
+	It("Should reject query with both text and event or with none", func() {
+		_, err := apiService.DoQuery(Query{
+			Query:     []string{"Hello"},
+			Event:     &Event{Name: "WELCOME"},
+			SessionID: "111",
+		})
+		Ω(errors.Is(err, ErrInvalidQuery)).Should(BeTrue())
+
+		_, err = apiService.DoQuery(Query{SessionID: "111"})
+		Ω(errors.Is(err, ErrInvalidQuery)).Should(BeTrue())
+		Ω(server.ReceivedRequests()).Should(HaveLen(0))
+	})
+})
@@ -0,0 +1,99 @@
+package gapiai
+
+/***********************************************************************************************************************
+ *
+ * Go client-side library for API.AI
+ * =================================================
+ *
+ * Copyright (C) 2017 by Slava Vasylyev
+ *
+ *
+ * *********************************************************************************************************************
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance with
+ * the License. You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ *
+ ***********************************************************************************************************************/
+
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+//Hypothesis is a speech recognition result of an external recognizer.
+type Hypothesis struct {
+	Text       string
+	Confidence float32
+}
+
+func (service *QueryService) HypothesesRequest(sessionID string, hypotheses []Hypothesis) (*QueryResponse, error) {
+	return service.HypothesesRequestContext(context.Background(), sessionID, hypotheses)
+}
+
+//HypothesesRequestContext sends several recognition hypotheses as one query. Hypotheses are normalized
+//with NormalizeHypotheses before sending.
+func (service *QueryService) HypothesesRequestContext(ctx context.Context, sessionID string, hypotheses []Hypothesis) (*QueryResponse, error) {
+	hypotheses, err := NormalizeHypotheses(hypotheses)
+	if err != nil {
+		return nil, err
+	}
+	q := Query{
+		Query:      make([]string, len(hypotheses)),
+		Confidence: make([]float32, len(hypotheses)),
+		SessionID:  sessionID,
+	}
+	for i, h := range hypotheses {
+		q.Query[i] = h.Text
+		q.Confidence[i] = h.Confidence
+	}
+	return service.DoQueryContext(ctx, q)
+}
+
+//NormalizeHypotheses validates and normalizes hypotheses. Text is trimmed and its whitespace collapsed,
+//duplicate texts are merged keeping the highest confidence, and the result is sorted by confidence,
+//most confident first. Confidences are kept as reported by the recognizer, they are scores of
+//separate hypotheses and need not sum to 1. ErrInvalidQuery is returned for empty text or confidence not in [0, 1].
+func NormalizeHypotheses(hypotheses []Hypothesis) ([]Hypothesis, error) {
+	if len(hypotheses) == 0 {
+		return nil, fmt.Errorf("%w: no hypotheses", ErrInvalidQuery)
+	}
+
+	result := make([]Hypothesis, 0, len(hypotheses))
+	index := make(map[string]int, len(hypotheses))
+	for _, h := range hypotheses {
+		text := strings.Join(strings.Fields(h.Text), " ")
+		if text == "" {
+			return nil, fmt.Errorf("%w: hypothesis text is empty", ErrInvalidQuery)
+		}
+		if !validConfidence(h.Confidence) {
+			return nil, fmt.Errorf("%w: confidence %v of %q is not in [0, 1]", ErrInvalidQuery, h.Confidence, text)
+		}
+		if i, ok := index[text]; ok {
+			if h.Confidence > result[i].Confidence {
+				result[i].Confidence = h.Confidence
+			}
+			continue
+		}
+		index[text] = len(result)
+		result = append(result, Hypothesis{Text: text, Confidence: h.Confidence})
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Confidence > result[j].Confidence
+	})
+	return result, nil
+}
+
+//validConfidence reports whether c is in [0, 1], NaN is not.
+func validConfidence(c float32) bool {
+	return c >= 0 && c <= 1
+}
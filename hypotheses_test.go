@@ -0,0 +1,119 @@
+package gapiai_test
+
+/***********************************************************************************************************************
+ *
+ * Go client-side library for API.AI
+ * =================================================
+ *
+ * Copyright (C) 2017 by Slava Vasylyev
+ *
+ *
+ * *********************************************************************************************************************
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance with
+ * the License. You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ *
+ ***********************************************************************************************************************/
+
+
+import (
+	. "github.com/slavaVA/go-api.ai"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"errors"
+	"github.com/onsi/gomega/ghttp"
+	"math"
+	"net/http"
+)
+
+var _ = Describe("Hypotheses", func() {
+	var server *ghttp.Server
+	var apiService *QueryService
+
+	BeforeEach(func() {
+		server = ghttp.NewServer()
+		apiConfig := &ApiConfig{
+			AccessToken: "123456789",
+			Lang:        English,
+		}
+		apiService = NewQueryAPIEndpoint(server.URL()+"/v1/", CurrentAPIVersion, apiConfig)
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("Should send hypotheses as parallel query and confidence arrays", func() {
+		server.AppendHandlers(
+			ghttp.CombineHandlers(
+				ghttp.VerifyRequest("POST", "/v1/query", "v=20150910"),
+				ghttp.VerifyJSON(`{
+					"query": ["turn on the light", "turn on the lights", "turn of the light"],
+					"confidence": [0.5, 0.25, 0.125],
+					"lang": "en",
+					"sessionId": "111"
+				}`),
+				ghttp.RespondWith(http.StatusOK, `{"result": {"resolvedQuery": "turn on the light"}, "status": {"code": 200}, "sessionId": "111"}`),
+			),
+		)
+		response, err := apiService.HypothesesRequest("111", []Hypothesis{
+			{"turn of the light", 0.125},
+			{"  turn on   the light ", 0.5},
+			{"turn on the lights", 0.25},
+		})
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(response.Result.ResolvedQuery).Should(Equal("turn on the light"))
+	})
+
+	It("Should dedupe and sort hypotheses keeping reported confidences", func() {
+		hypotheses, err := NormalizeHypotheses([]Hypothesis{
+			{"lights on", 0.5},
+			{"light son", 0.85},
+			{"lights\ton", 0.9},
+		})
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(hypotheses).Should(Equal([]Hypothesis{
+			{"lights on", 0.9},
+			{"light son", 0.85},
+		}))
+	})
+
+	It("Should reject invalid hypotheses", func() {
+		invalid := [][]Hypothesis{
+			nil,
+			{{" ", 0.5}},
+			{{"hello", -0.1}},
+			{{"hello", 1.5}},
+			{{"hello", float32(math.NaN())}},
+		}
+		for _, hypotheses := range invalid {
+			_, err := apiService.HypothesesRequest("111", hypotheses)
+			Ω(errors.Is(err, ErrInvalidQuery)).Should(BeTrue(), "%v", hypotheses)
+		}
+		Ω(server.ReceivedRequests()).Should(HaveLen(0))
+	})
+
+	It("Should reject query with mismatched confidence", func() {
+		_, err := apiService.DoQuery(Query{
+			Query:      []string{"hello", "yellow"},
+			Confidence: []float32{0.9},
+			SessionID:  "111",
+		})
+		Ω(errors.Is(err, ErrInvalidQuery)).Should(BeTrue())
+
+		_, err = apiService.DoQuery(Query{
+			Query:      []string{"hello"},
+			Confidence: []float32{2},
+			SessionID:  "111",
+		})
+		Ω(errors.Is(err, ErrInvalidQuery)).Should(BeTrue())
+		Ω(server.ReceivedRequests()).Should(HaveLen(0))
+	})
+})
@@ -0,0 +1,116 @@
+package gapiai
+
+/***********************************************************************************************************************
+ *
+ * Go client-side library for API.AI
+ * =================================================
+ *
+ * Copyright (C) 2017 by Slava Vasylyev
+ *
+ *
+ * *********************************************************************************************************************
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance with
+ * the License. You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ *
+ ***********************************************************************************************************************/
+
+
+import (
+	"context"
+	"fmt"
+)
+
+type (
+	IntentsService struct {
+		ApiService
+		url     string
+		version string
+	}
+)
+
+func NewIntentsAPIEndpoint(url string, version string, cfg *ApiConfig) *IntentsService {
+	svc := &IntentsService{
+		ApiService: ApiService{
+			logger:    nil,
+			Config:    cfg,
+			developer: true,
+		},
+		url:     fmt.Sprint(url, "intents"),
+		version: version,
+	}
+	return svc
+}
+
+func DefaultIntentsAPIEndpoint(cfg *ApiConfig) *IntentsService {
+	return NewIntentsAPIEndpoint(apiAiURL, CurrentAPIVersion, cfg)
+}
+
+func (service *IntentsService) intentsURL(path ...string) string {
+	return endpointURL(service.url, service.version, nil, path...)
+}
+
+//List returns descriptions of all agent intents.
+func (service *IntentsService) List() ([]IntentDescription, error) {
+	return service.ListContext(context.Background())
+}
+
+func (service *IntentsService) ListContext(ctx context.Context) ([]IntentDescription, error) {
+	var intents []IntentDescription
+	if err := service.doJSON(ctx, "GET", service.intentsURL(), nil, &intents); err != nil {
+		return nil, err
+	}
+	return intents, nil
+}
+
+//Get returns the intent by its ID.
+func (service *IntentsService) Get(iid string) (*Intent, error) {
+	return service.GetContext(context.Background(), iid)
+}
+
+func (service *IntentsService) GetContext(ctx context.Context, iid string) (*Intent, error) {
+	intent := &Intent{}
+	if err := service.doJSON(ctx, "GET", service.intentsURL(iid), nil, intent); err != nil {
+		return nil, err
+	}
+	return intent, nil
+}
+
+//Create creates the intent and returns its ID.
+func (service *IntentsService) Create(intent Intent) (string, error) {
+	return service.CreateContext(context.Background(), intent)
+}
+
+func (service *IntentsService) CreateContext(ctx context.Context, intent Intent) (string, error) {
+	var response struct {
+		ID string `json:"id"`
+	}
+	if err := service.doJSON(ctx, "POST", service.intentsURL(), intent, &response); err != nil {
+		return "", err
+	}
+	return response.ID, nil
+}
+
+//Update replaces the intent.
+func (service *IntentsService) Update(iid string, intent Intent) error {
+	return service.UpdateContext(context.Background(), iid, intent)
+}
+
+func (service *IntentsService) UpdateContext(ctx context.Context, iid string, intent Intent) error {
+	return service.doJSON(ctx, "PUT", service.intentsURL(iid), intent, nil)
+}
+
+//Delete removes the intent.
+func (service *IntentsService) Delete(iid string) error {
+	return service.DeleteContext(context.Background(), iid)
+}
+
+func (service *IntentsService) DeleteContext(ctx context.Context, iid string) error {
+	return service.doJSON(ctx, "DELETE", service.intentsURL(iid), nil, nil)
+}
@@ -0,0 +1,210 @@
+package gapiai_test
+
+/***********************************************************************************************************************
+ *
+ * Go client-side library for API.AI
+ * =================================================
+ *
+ * Copyright (C) 2017 by Slava Vasylyev
+ *
+ *
+ * *********************************************************************************************************************
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance with
+ * the License. You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ *
+ ***********************************************************************************************************************/
+
+
+import (
+	. "github.com/slavaVA/go-api.ai"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/ghttp"
+	"net/http"
+)
+
+var _ = Describe("Intents", func() {
+	var server *ghttp.Server
+	var apiService *IntentsService
+	success := `{"status": {"code": 200, "errorType": "success"}}`
+	intentJSON := `{
+		"id": "i1",
+		"name": "change appliance state",
+		"auto": true,
+		"contexts": ["house"],
+		"templates": ["turn @state:state the @appliance:appliance "],
+		"userSays": [{
+			"id": "u1",
+			"data": [
+				{"text": "turn "},
+				{"text": "on", "alias": "state", "meta": "@state", "userDefined": true},
+				{"text": " the "},
+				{"text": "kitchen lights", "alias": "appliance", "meta": "@appliance"}
+			],
+			"isTemplate": false,
+			"count": 0
+		}],
+		"responses": [{
+			"action": "set-appliance",
+			"resetContexts": false,
+			"affectedContexts": [{"name": "house", "parameters": {}, "lifespan": 10}],
+			"parameters": [{
+				"id": "p1",
+				"name": "appliance",
+				"value": "$appliance",
+				"dataType": "@appliance",
+				"required": true,
+				"prompts": ["Which appliance?"],
+				"isList": false
+			}],
+			"messages": [{"type": 0, "speech": "Turning $state the $appliance"}]
+		}],
+		"priority": 500000,
+		"events": [{"name": "APPLIANCE"}],
+		"webhookUsed": true,
+		"webhookForSlotFilling": false,
+		"fallbackIntent": false
+	}`
+
+	BeforeEach(func() {
+		server = ghttp.NewServer()
+		apiConfig := &ApiConfig{
+			DeveloperAccessToken: "developer",
+			Lang:                 English,
+		}
+		apiService = NewIntentsAPIEndpoint(server.URL()+"/v1/", CurrentAPIVersion, apiConfig)
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("Should list intents", func() {
+		server.AppendHandlers(
+			ghttp.CombineHandlers(
+				ghttp.VerifyRequest("GET", "/v1/intents", "v=20150910"),
+				ghttp.VerifyHeader(http.Header{
+					"Authorization": []string{"Bearer developer"},
+				}),
+				ghttp.RespondWith(http.StatusOK, `[{
+					"id": "i1",
+					"name": "change appliance state",
+					"contextIn": ["house"],
+					"contextOut": [{"name": "house", "lifespan": 10}],
+					"events": [{"name": "APPLIANCE"}],
+					"parameters": [{"name": "appliance", "value": "$appliance", "dataType": "@appliance", "required": true}],
+					"actions": ["set-appliance"],
+					"priority": 500000,
+					"fallbackIntent": false
+				}]`),
+			),
+		)
+		intents, err := apiService.List()
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(intents).Should(HaveLen(1))
+		Ω(intents[0].ContextOut[0].Lifespan).Should(Equal(10))
+		Ω(intents[0].Events[0].Name).Should(Equal("APPLIANCE"))
+		Ω(intents[0].Actions).Should(Equal([]string{"set-appliance"}))
+	})
+
+	It("Should decode full intent model", func() {
+		server.AppendHandlers(
+			ghttp.CombineHandlers(
+				ghttp.VerifyRequest("GET", "/v1/intents/i1", "v=20150910"),
+				ghttp.RespondWith(http.StatusOK, intentJSON),
+			),
+		)
+		intent, err := apiService.Get("i1")
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(intent.Name).Should(Equal("change appliance state"))
+		Ω(intent.UserSays[0].Data).Should(HaveLen(4))
+		Ω(intent.UserSays[0].Data[1]).Should(Equal(UserSaysPart{
+			Text: "on", Alias: "state", Meta: "@state", UserDefined: true,
+		}))
+		Ω(intent.Responses[0].AffectedContexts[0].Name).Should(Equal("house"))
+		Ω(intent.Responses[0].Parameters[0].Prompts).Should(Equal([]string{"Which appliance?"}))
+		Ω(intent.WebhookUsed).Should(BeTrue())
+	})
+
+	It("Should create, update and delete intent", func() {
+		server.AppendHandlers(
+			ghttp.CombineHandlers(
+				ghttp.VerifyRequest("GET", "/v1/intents/i1", "v=20150910"),
+				ghttp.RespondWith(http.StatusOK, intentJSON),
+			),
+			ghttp.CombineHandlers(
+				ghttp.VerifyRequest("POST", "/v1/intents", "v=20150910"),
+				ghttp.VerifyJSON(intentJSON),
+				ghttp.RespondWith(http.StatusOK, `{"id": "i1", "status": {"code": 200, "errorType": "success"}}`),
+			),
+			ghttp.CombineHandlers(
+				ghttp.VerifyRequest("PUT", "/v1/intents/i1", "v=20150910"),
+				ghttp.VerifyJSON(intentJSON),
+				ghttp.RespondWith(http.StatusOK, success),
+			),
+			ghttp.CombineHandlers(
+				ghttp.VerifyRequest("DELETE", "/v1/intents/i1", "v=20150910"),
+				ghttp.RespondWith(http.StatusOK, success),
+			),
+		)
+		intent, err := apiService.Get("i1")
+		Ω(err).ShouldNot(HaveOccurred())
+
+		id, err := apiService.Create(*intent)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(id).Should(Equal("i1"))
+		Ω(apiService.Update(id, *intent)).Should(Succeed())
+		Ω(apiService.Delete(id)).Should(Succeed())
+		Ω(server.ReceivedRequests()).Should(HaveLen(4))
+	})
+
+	It("Should keep unmodelled fields when updating fetched intent", func() {
+		server.AppendHandlers(
+			ghttp.RespondWith(http.StatusOK, `{
+				"id": "i2",
+				"name": "x",
+				"lastUpdate": 123,
+				"cortanaCommand": {"navigateOrService": "NAVIGATE", "target": ""},
+				"responses": [{
+					"speech": "s",
+					"defaultResponsePlatforms": {"google": true},
+					"messages": [{"type": 0, "lang": "en", "speech": "s"}]
+				}]
+			}`),
+			ghttp.CombineHandlers(
+				ghttp.VerifyRequest("PUT", "/v1/intents/i2", "v=20150910"),
+				ghttp.VerifyJSON(`{
+					"id": "i2",
+					"name": "x",
+					"auto": false,
+					"priority": 0,
+					"webhookUsed": false,
+					"webhookForSlotFilling": false,
+					"fallbackIntent": false,
+					"lastUpdate": 123,
+					"cortanaCommand": {"navigateOrService": "NAVIGATE", "target": ""},
+					"responses": [{
+						"resetContexts": false,
+						"speech": "s",
+						"defaultResponsePlatforms": {"google": true},
+						"messages": [{"type": 0, "lang": "en", "speech": "s"}]
+					}]
+				}`),
+				ghttp.RespondWith(http.StatusOK, success),
+			),
+		)
+		intent, err := apiService.Get("i2")
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(intent.Extra).Should(HaveKey("lastUpdate"))
+		Ω(intent.Responses[0].Extra).Should(HaveKey("speech"))
+		Ω(apiService.Update("i2", *intent)).Should(Succeed())
+	})
+})
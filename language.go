@@ -0,0 +1,186 @@
+package gapiai
+
+/***********************************************************************************************************************
+ *
+ * Go client-side library for API.AI
+ * =================================================
+ *
+ * Copyright (C) 2017 by Slava Vasylyev
+ *
+ *
+ * *********************************************************************************************************************
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance with
+ * the License. You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ *
+ ***********************************************************************************************************************/
+
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+//MatchConfidence tells how well a language tag matches the supported language.
+type MatchConfidence int
+
+const (
+	//NoMatch means no supported language was found.
+	NoMatch MatchConfidence = iota
+	//LowMatch means only the base language matches and a different region was chosen, e.g. "zh" for "zh-CN".
+	LowMatch
+	//HighMatch means the tag is a region variant of a supported base language, e.g. "en-GB" for "en".
+	HighMatch
+	//ExactMatch means the tag is the supported language itself.
+	ExactMatch
+)
+
+func (c MatchConfidence) String() string {
+	switch c {
+	case LowMatch:
+		return "Low"
+	case HighMatch:
+		return "High"
+	case ExactMatch:
+		return "Exact"
+	}
+	return "No"
+}
+
+//NormalizeLanguageTag formats a BCP-47 tag the way SupportedLang constants are written: "-" separators,
+//lower case language, title case script and upper case region, so "pt_br" becomes "pt-BR".
+func NormalizeLanguageTag(tag string) string {
+	subtags := strings.Split(strings.Replace(strings.TrimSpace(tag), "_", "-", -1), "-")
+	for i, s := range subtags {
+		switch {
+		case i == 0:
+			subtags[i] = strings.ToLower(s)
+		case len(s) == 2:
+			subtags[i] = strings.ToUpper(s)
+		case len(s) == 4:
+			subtags[i] = strings.ToUpper(s[:1]) + strings.ToLower(s[1:])
+		default:
+			subtags[i] = strings.ToLower(s)
+		}
+	}
+	return strings.Join(subtags, "-")
+}
+
+//scriptRegions maps scripts to the region supported for them, when the tag has no region.
+var scriptRegions = map[string]string{
+	"zh-Hans": "CN",
+	"zh-Hant": "TW",
+}
+
+//MatchLanguage finds the supported language for the BCP-47 tag. The script subtag is dropped, so "zh-Hant-TW"
+//matches "zh-TW", and a tag with script only uses the region of the script, so "zh-Hant" matches "zh-TW" too.
+//A region variant falls back to its base language, and a base language without its own entry falls back to
+//the first supported regional variant.
+func MatchLanguage(tag string) (SupportedLang, MatchConfidence) {
+	tag = NormalizeLanguageTag(tag)
+	if tag == "" {
+		return "", NoMatch
+	}
+	subtags := strings.Split(tag, "-")
+	base := subtags[0]
+	var script, region string
+	for _, s := range subtags[1:] {
+		switch {
+		case len(s) == 4 && script == "":
+			script = s
+		case (len(s) == 2 || len(s) == 3) && region == "":
+			region = s
+		}
+	}
+	if region == "" && script != "" {
+		region = scriptRegions[base+"-"+script]
+	}
+
+	var regional, variant SupportedLang
+	for _, l := range SupportedLanguages {
+		switch {
+		case string(l) == tag:
+			return l, ExactMatch
+		case region != "" && string(l) == base+"-"+region:
+			regional = l
+		case variant == "" && strings.HasPrefix(string(l), base+"-"):
+			variant = l
+		}
+	}
+	if regional != "" {
+		return regional, HighMatch
+	}
+	for _, l := range SupportedLanguages {
+		if string(l) == base {
+			return l, HighMatch
+		}
+	}
+	if variant != "" {
+		return variant, LowMatch
+	}
+	return "", NoMatch
+}
+
+//NegotiateLanguage picks the supported language for an Accept-Language header like "de-CH,de;q=0.9".
+//The language with the highest q-value that matches at all wins, the match confidence only decides
+//between languages with equal q-values.
+func NegotiateLanguage(acceptLanguage string) (SupportedLang, MatchConfidence) {
+	var best SupportedLang
+	bestConfidence := NoMatch
+	bestQ := 0.0
+	for _, t := range parseAcceptLanguage(acceptLanguage) {
+		if best != "" && t.q < bestQ {
+			break
+		}
+		lang, confidence := MatchLanguage(t.tag)
+		if confidence > bestConfidence {
+			best, bestConfidence, bestQ = lang, confidence, t.q
+		}
+	}
+	return best, bestConfidence
+}
+
+//weightedTag is a language tag of Accept-Language header with its q-value.
+type weightedTag struct {
+	tag string
+	q   float64
+}
+
+//parseAcceptLanguage returns language tags of the header sorted by q-value. Wildcards, tags with zero
+//or malformed q-values are skipped.
+func parseAcceptLanguage(header string) []weightedTag {
+	var tags []weightedTag
+	for _, part := range strings.Split(header, ",") {
+		fields := strings.Split(part, ";")
+		tag := strings.TrimSpace(fields[0])
+		if tag == "" || tag == "*" {
+			continue
+		}
+		q := 1.0
+		for _, param := range fields[1:] {
+			param = strings.TrimSpace(param)
+			if !strings.HasPrefix(param, "q=") {
+				continue
+			}
+			v, err := strconv.ParseFloat(param[2:], 64)
+			if err != nil || v < 0 || v > 1 {
+				v = 0
+			}
+			q = v
+		}
+		if q > 0 {
+			tags = append(tags, weightedTag{tag, q})
+		}
+	}
+	sort.SliceStable(tags, func(i, j int) bool {
+		return tags[i].q > tags[j].q
+	})
+	return tags
+}
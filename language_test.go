@@ -0,0 +1,74 @@
+package gapiai_test
+
+/***********************************************************************************************************************
+ *
+ * Go client-side library for API.AI
+ * =================================================
+ *
+ * Copyright (C) 2017 by Slava Vasylyev
+ *
+ *
+ * *********************************************************************************************************************
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance with
+ * the License. You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ *
+ ***********************************************************************************************************************/
+
+
+import (
+	. "github.com/slavaVA/go-api.ai"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Language", func() {
+	It("Should normalize tags", func() {
+		Ω(NormalizeLanguageTag("pt_br")).Should(Equal("pt-BR"))
+		Ω(NormalizeLanguageTag(" EN-us ")).Should(Equal("en-US"))
+		Ω(NormalizeLanguageTag("zh-hant-tw")).Should(Equal("zh-Hant-TW"))
+	})
+
+	It("Should match tags to supported languages", func() {
+		match := func(tag string) []interface{} {
+			lang, confidence := MatchLanguage(tag)
+			return []interface{}{lang, confidence}
+		}
+		Ω(match("pt_BR")).Should(Equal([]interface{}{PortugueseBrazil, ExactMatch}))
+		Ω(match("en-GB")).Should(Equal([]interface{}{English, HighMatch}))
+		Ω(match("de-CH")).Should(Equal([]interface{}{German, HighMatch}))
+		Ω(match("zh")).Should(Equal([]interface{}{ChineseChina, LowMatch}))
+		Ω(match("zh-SG")).Should(Equal([]interface{}{ChineseChina, LowMatch}))
+		Ω(match("zh-Hant-TW")).Should(Equal([]interface{}{ChineseTaiwan, HighMatch}))
+		Ω(match("zh_hant_hk")).Should(Equal([]interface{}{ChineseHongKong, HighMatch}))
+		Ω(match("zh-Hant")).Should(Equal([]interface{}{ChineseTaiwan, HighMatch}))
+		Ω(match("zh-Hans")).Should(Equal([]interface{}{ChineseChina, HighMatch}))
+		Ω(match("en-Latn-GB")).Should(Equal([]interface{}{English, HighMatch}))
+		Ω(match("uk")).Should(Equal([]interface{}{SupportedLang(""), NoMatch}))
+		Ω(match("")).Should(Equal([]interface{}{SupportedLang(""), NoMatch}))
+	})
+
+	It("Should negotiate Accept-Language header", func() {
+		negotiate := func(header string) []interface{} {
+			lang, confidence := NegotiateLanguage(header)
+			return []interface{}{lang, confidence}
+		}
+		Ω(negotiate("de-CH,de;q=0.9")).Should(Equal([]interface{}{German, HighMatch}))
+		Ω(negotiate("uk;q=1, ru-RU;q=0.8, en;q=0.9")).Should(Equal([]interface{}{English, ExactMatch}))
+		Ω(negotiate("zh;q=0.9, fr;q=0.5")).Should(Equal([]interface{}{ChineseChina, LowMatch}))
+		Ω(negotiate("zh, fr")).Should(Equal([]interface{}{French, ExactMatch}))
+		Ω(negotiate("uk, de-AT;q=0.7, en;q=0.7")).Should(Equal([]interface{}{English, ExactMatch}))
+		Ω(negotiate("zh, uk;q=0.5")).Should(Equal([]interface{}{ChineseChina, LowMatch}))
+		Ω(negotiate("zh-Hant-TW,zh;q=0.8")).Should(Equal([]interface{}{ChineseTaiwan, HighMatch}))
+		Ω(negotiate("fr;q=0, it;q=bad, *;q=0.1")).Should(Equal([]interface{}{SupportedLang(""), NoMatch}))
+		Ω(negotiate("")).Should(Equal([]interface{}{SupportedLang(""), NoMatch}))
+		Ω(LowMatch.String()).Should(Equal("Low"))
+	})
+})
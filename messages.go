@@ -0,0 +1,442 @@
+package gapiai
+
+/***********************************************************************************************************************
+ *
+ * Go client-side library for API.AI
+ * =================================================
+ *
+ * Copyright (C) 2017 by Slava Vasylyev
+ *
+ *
+ * *********************************************************************************************************************
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance with
+ * the License. You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ *
+ ***********************************************************************************************************************/
+
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+)
+
+type (
+	//MessageType is the "type" of a response message. API.AI sends it either as a number for
+	//messages common to all platforms or as a string for platform specific ones, like "simple_response".
+	MessageType string
+
+	//Message is a rich response message. Messages are decoded into *TextMessage, *CardMessage,
+	//*QuickRepliesMessage, *ImageMessage, *CustomPayloadMessage, *SimpleResponseMessage or
+	//*UnknownMessage for types not modelled by this package.
+	Message interface {
+		MessageType() MessageType
+		//MessagePlatform is the platform the message is intended for, empty for the default one.
+		MessagePlatform() string
+	}
+
+	//Messages is a list of rich response messages, decoded by the "type" of each message.
+	Messages []Message
+
+	//TextMessage is a text response. API.AI picks one of Speech variants at random.
+	TextMessage struct {
+		Platform string
+		Speech   []string
+		//Extra holds message fields not modelled by the type, e.g. "lang". They are encoded back as is.
+		Extra map[string]json.RawMessage
+		//speechList keeps speech encoded as a list even if it has a single variant
+		speechList bool
+	}
+
+	//CardMessage is a card with an image and buttons.
+	CardMessage struct {
+		Platform string       `json:"platform,omitempty"`
+		Title    string       `json:"title,omitempty"`
+		Subtitle string       `json:"subtitle,omitempty"`
+		ImageURL string       `json:"imageUrl,omitempty"`
+		Buttons  []CardButton `json:"buttons,omitempty"`
+		//Extra holds message fields not modelled by the type. They are encoded back as is.
+		Extra map[string]json.RawMessage `json:"-"`
+	}
+
+	CardButton struct {
+		Text string `json:"text"`
+		//Postback is the text sent back to the agent or the URL opened when the button is tapped.
+		Postback string `json:"postback,omitempty"`
+	}
+
+	//QuickRepliesMessage suggests replies to a user.
+	QuickRepliesMessage struct {
+		Platform string   `json:"platform,omitempty"`
+		Title    string   `json:"title,omitempty"`
+		Replies  []string `json:"replies"`
+		//Extra holds message fields not modelled by the type. They are encoded back as is.
+		Extra map[string]json.RawMessage `json:"-"`
+	}
+
+	//ImageMessage is an image response.
+	ImageMessage struct {
+		Platform string `json:"platform,omitempty"`
+		ImageURL string `json:"imageUrl"`
+		//Extra holds message fields not modelled by the type. They are encoded back as is.
+		Extra map[string]json.RawMessage `json:"-"`
+	}
+
+	//CustomPayloadMessage carries an arbitrary JSON payload for the platform.
+	CustomPayloadMessage struct {
+		Platform string          `json:"platform,omitempty"`
+		Payload  json.RawMessage `json:"payload"`
+		//Extra holds message fields not modelled by the type. They are encoded back as is.
+		Extra map[string]json.RawMessage `json:"-"`
+	}
+
+	//SimpleResponseMessage is the Actions on Google speech bubble.
+	SimpleResponseMessage struct {
+		Platform     string `json:"platform,omitempty"`
+		TextToSpeech string `json:"textToSpeech,omitempty"`
+		SSML         string `json:"ssml,omitempty"`
+		DisplayText  string `json:"displayText,omitempty"`
+		//Extra holds message fields not modelled by the type. They are encoded back as is.
+		Extra map[string]json.RawMessage `json:"-"`
+	}
+
+	//UnknownMessage keeps a message of a type not modelled by this package as is.
+	UnknownMessage struct {
+		Type     MessageType
+		Platform string
+		Raw      json.RawMessage
+	}
+)
+
+const (
+	TextMessageType           MessageType = "0"
+	CardMessageType           MessageType = "1"
+	QuickRepliesMessageType   MessageType = "2"
+	ImageMessageType          MessageType = "3"
+	CustomPayloadMessageType  MessageType = "4"
+	SimpleResponseMessageType MessageType = "simple_response"
+)
+
+func (t MessageType) MarshalJSON() ([]byte, error) {
+	if n, err := strconv.Atoi(string(t)); err == nil {
+		return json.Marshal(n)
+	}
+	return json.Marshal(string(t))
+}
+
+func (t *MessageType) UnmarshalJSON(data []byte) error {
+	var n json.Number
+	if err := json.Unmarshal(data, &n); err == nil {
+		*t = MessageType(n.String())
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	*t = MessageType(s)
+	return nil
+}
+
+func (m *TextMessage) MessageType() MessageType           { return TextMessageType }
+func (m *CardMessage) MessageType() MessageType           { return CardMessageType }
+func (m *QuickRepliesMessage) MessageType() MessageType   { return QuickRepliesMessageType }
+func (m *ImageMessage) MessageType() MessageType          { return ImageMessageType }
+func (m *CustomPayloadMessage) MessageType() MessageType  { return CustomPayloadMessageType }
+func (m *SimpleResponseMessage) MessageType() MessageType { return SimpleResponseMessageType }
+func (m *UnknownMessage) MessageType() MessageType        { return m.Type }
+
+func (m *TextMessage) MessagePlatform() string           { return m.Platform }
+func (m *CardMessage) MessagePlatform() string           { return m.Platform }
+func (m *QuickRepliesMessage) MessagePlatform() string   { return m.Platform }
+func (m *ImageMessage) MessagePlatform() string          { return m.Platform }
+func (m *CustomPayloadMessage) MessagePlatform() string  { return m.Platform }
+func (m *SimpleResponseMessage) MessagePlatform() string { return m.Platform }
+func (m *UnknownMessage) MessagePlatform() string        { return m.Platform }
+
+//NewTextMessage returns a text message for the default platform.
+func NewTextMessage(speech ...string) *TextMessage {
+	return &TextMessage{Speech: speech}
+}
+
+//Text returns the first speech variant.
+func (m *TextMessage) Text() string {
+	if len(m.Speech) == 0 {
+		return ""
+	}
+	return m.Speech[0]
+}
+
+func (m *TextMessage) MarshalJSON() ([]byte, error) {
+	var speech interface{} = m.Speech
+	if len(m.Speech) == 1 && !m.speechList {
+		speech = m.Speech[0]
+	}
+	return marshalMessage(TextMessageType, struct {
+		Platform string      `json:"platform,omitempty"`
+		Speech   interface{} `json:"speech"`
+	}{m.Platform, speech}, m.Extra)
+}
+
+func (m *TextMessage) UnmarshalJSON(data []byte) error {
+	var v struct {
+		Platform string          `json:"platform"`
+		Speech   json.RawMessage `json:"speech"`
+	}
+	extra, err := unmarshalMessage(data, &v)
+	if err != nil {
+		return err
+	}
+	m.Platform = v.Platform
+	m.Extra = extra
+	m.Speech = nil
+	m.speechList = false
+	speech := bytes.TrimSpace(v.Speech)
+	if len(speech) == 0 || string(speech) == "null" {
+		return nil
+	}
+	if speech[0] == '[' {
+		m.speechList = true
+		return json.Unmarshal(speech, &m.Speech)
+	}
+	var s string
+	if err := json.Unmarshal(speech, &s); err != nil {
+		return err
+	}
+	m.Speech = []string{s}
+	return nil
+}
+
+func (m *CardMessage) MarshalJSON() ([]byte, error) {
+	type card CardMessage
+	return marshalMessage(CardMessageType, (*card)(m), m.Extra)
+}
+
+func (m *CardMessage) UnmarshalJSON(data []byte) (err error) {
+	type card CardMessage
+	m.Extra, err = unmarshalMessage(data, (*card)(m))
+	return
+}
+
+func (m *QuickRepliesMessage) MarshalJSON() ([]byte, error) {
+	type quickReplies QuickRepliesMessage
+	return marshalMessage(QuickRepliesMessageType, (*quickReplies)(m), m.Extra)
+}
+
+func (m *QuickRepliesMessage) UnmarshalJSON(data []byte) (err error) {
+	type quickReplies QuickRepliesMessage
+	m.Extra, err = unmarshalMessage(data, (*quickReplies)(m))
+	return
+}
+
+func (m *ImageMessage) MarshalJSON() ([]byte, error) {
+	type image ImageMessage
+	return marshalMessage(ImageMessageType, (*image)(m), m.Extra)
+}
+
+func (m *ImageMessage) UnmarshalJSON(data []byte) (err error) {
+	type image ImageMessage
+	m.Extra, err = unmarshalMessage(data, (*image)(m))
+	return
+}
+
+func (m *CustomPayloadMessage) MarshalJSON() ([]byte, error) {
+	type customPayload CustomPayloadMessage
+	return marshalMessage(CustomPayloadMessageType, (*customPayload)(m), m.Extra)
+}
+
+func (m *CustomPayloadMessage) UnmarshalJSON(data []byte) (err error) {
+	type customPayload CustomPayloadMessage
+	m.Extra, err = unmarshalMessage(data, (*customPayload)(m))
+	return
+}
+
+func (m *SimpleResponseMessage) MarshalJSON() ([]byte, error) {
+	type simpleResponse SimpleResponseMessage
+	return marshalMessage(SimpleResponseMessageType, (*simpleResponse)(m), m.Extra)
+}
+
+func (m *SimpleResponseMessage) UnmarshalJSON(data []byte) (err error) {
+	type simpleResponse SimpleResponseMessage
+	m.Extra, err = unmarshalMessage(data, (*simpleResponse)(m))
+	return
+}
+
+//MarshalJSON returns Raw as is. A message without Raw, e.g. built by hand, is encoded from Type and Platform.
+func (m *UnknownMessage) MarshalJSON() ([]byte, error) {
+	if len(m.Raw) > 0 {
+		return m.Raw, nil
+	}
+	return marshalMessage(m.Type, &struct {
+		Platform string `json:"platform,omitempty"`
+	}{m.Platform}, nil)
+}
+
+//marshalMessage encodes v, which must encode to a JSON object, with the "type" field prepended and
+//extra fields added.
+func marshalMessage(t MessageType, v interface{}, extra map[string]json.RawMessage) ([]byte, error) {
+	typeJSON, err := json.Marshal(t)
+	if err != nil {
+		return nil, err
+	}
+	data, err := marshalWithExtra(v, extra)
+	if err != nil {
+		return nil, err
+	}
+	buf := bytes.NewBufferString(`{"type":`)
+	buf.Write(typeJSON)
+	if fields := bytes.TrimSpace(data[1:]); len(fields) > 1 {
+		buf.WriteByte(',')
+	}
+	buf.Write(data[1:])
+	return buf.Bytes(), nil
+}
+
+//unmarshalMessage decodes data into v like unmarshalWithExtra. The "type" field is not kept as extra,
+//it is encoded from the message type.
+func unmarshalMessage(data []byte, v interface{}) (map[string]json.RawMessage, error) {
+	extra, err := unmarshalWithExtra(data, v)
+	if err != nil {
+		return nil, err
+	}
+	delete(extra, "type")
+	if len(extra) == 0 {
+		return nil, nil
+	}
+	return extra, nil
+}
+
+func (messages *Messages) UnmarshalJSON(data []byte) error {
+	var raws []json.RawMessage
+	if err := json.Unmarshal(data, &raws); err != nil {
+		return err
+	}
+	if raws == nil {
+		*messages = nil
+		return nil
+	}
+	list := make(Messages, 0, len(raws))
+	for _, raw := range raws {
+		m, err := decodeMessage(raw)
+		if err != nil {
+			return err
+		}
+		list = append(list, m)
+	}
+	*messages = list
+	return nil
+}
+
+func decodeMessage(raw json.RawMessage) (Message, error) {
+	var head struct {
+		Type     MessageType `json:"type"`
+		Platform string      `json:"platform"`
+	}
+	if err := json.Unmarshal(raw, &head); err != nil {
+		return nil, err
+	}
+
+	var m Message
+	switch head.Type {
+	case TextMessageType:
+		m = &TextMessage{}
+	case CardMessageType:
+		m = &CardMessage{}
+	case QuickRepliesMessageType:
+		m = &QuickRepliesMessage{}
+	case ImageMessageType:
+		m = &ImageMessage{}
+	case CustomPayloadMessageType:
+		m = &CustomPayloadMessage{}
+	case SimpleResponseMessageType:
+		m = &SimpleResponseMessage{}
+	default:
+		return &UnknownMessage{
+			Type:     head.Type,
+			Platform: head.Platform,
+			Raw:      append(json.RawMessage(nil), raw...),
+		}, nil
+	}
+	if err := json.Unmarshal(raw, m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+//ForPlatform returns messages intended for the platform, empty platform selects default messages.
+func (messages Messages) ForPlatform(platform string) Messages {
+	var result Messages
+	for _, m := range messages {
+		if m != nil && m.MessagePlatform() == platform {
+			result = append(result, m)
+		}
+	}
+	return result
+}
+
+func (f *Fulfillment) TextMessages() []*TextMessage {
+	var result []*TextMessage
+	for _, m := range f.Messages {
+		if v, ok := m.(*TextMessage); ok {
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
+func (f *Fulfillment) Cards() []*CardMessage {
+	var result []*CardMessage
+	for _, m := range f.Messages {
+		if v, ok := m.(*CardMessage); ok {
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
+func (f *Fulfillment) QuickReplies() []*QuickRepliesMessage {
+	var result []*QuickRepliesMessage
+	for _, m := range f.Messages {
+		if v, ok := m.(*QuickRepliesMessage); ok {
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
+func (f *Fulfillment) Images() []*ImageMessage {
+	var result []*ImageMessage
+	for _, m := range f.Messages {
+		if v, ok := m.(*ImageMessage); ok {
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
+func (f *Fulfillment) CustomPayloads() []*CustomPayloadMessage {
+	var result []*CustomPayloadMessage
+	for _, m := range f.Messages {
+		if v, ok := m.(*CustomPayloadMessage); ok {
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
+func (f *Fulfillment) SimpleResponses() []*SimpleResponseMessage {
+	var result []*SimpleResponseMessage
+	for _, m := range f.Messages {
+		if v, ok := m.(*SimpleResponseMessage); ok {
+			result = append(result, v)
+		}
+	}
+	return result
+}
@@ -22,6 +22,7 @@ package gapiai
  ***********************************************************************************************************************/
 
 import (
+	"context"
 	"encoding/json"
 	"io"
 	"time"
@@ -30,7 +31,7 @@ import (
 type (
 	//Query the following parameters are used as either query parameters in the URL or JSON keys in the POST body
 	Query struct {
-		Query         []string        `json:"query"`
+		Query         []string        `json:"query,omitempty"`
 		Confidence    []float32       `json:"confidence,omitempty"`
 		Contexts      []DialogContext `json:"contexts,omitempty"`
 		ResetContexts bool            `json:"resetContexts,omitempty"`
@@ -57,8 +58,8 @@ type (
 	//it will result in different action than before, because of the new context.
 	DialogContext struct {
 		Name       string                 `json:"name"`
-		Parameters map[string]interface{} `json:"parameters"`
-		Lifespan   int                    `json:"lifespan"`
+		Parameters Parameters `json:"parameters"`
+		Lifespan   int        `json:"lifespan"`
 	}
 
 	//Event is a feature that allows you to invoke intents by an event name instead of a user query.
@@ -66,7 +67,7 @@ type (
 	///query request containing an "event" parameter.
 	Event struct {
 		Name string            `json:"name"`
-		Data map[string]string `json:"data"`
+		Data map[string]string `json:"data,omitempty"`
 	}
 
 	//Location contains latitude and longitude values.
@@ -90,6 +91,7 @@ type (
 	//Array of entities that replace developer defined entities for this request only.
 	//The entity(ies) need to exist in the developer console.
 	Entity struct {
+		ID      string        `json:"id,omitempty"`
 		Name    string        `json:"name"`
 		Entries []EntityEntry `json:"entries"`
 		Extend  bool          `json:"extend"`
@@ -101,6 +103,14 @@ type (
 		Synonyms []string `json:"synonyms"`
 	}
 
+	//EntityDescription is an item of the agent entities list.
+	EntityDescription struct {
+		ID      string `json:"id"`
+		Name    string `json:"name"`
+		Count   int    `json:"count"`
+		Preview string `json:"preview"`
+	}
+
 	//QueryResponse takes natural language text and information as JSON in the POST body and returns information as JSON.
 	QueryResponse struct {
 		ID        string       `json:"id"`
@@ -108,25 +118,34 @@ type (
 		Result    QueryResult  `json:"result"`
 		Status    StatusObject `json:"status"`
 		SessionID string       `json:"sessionId"`
+		//Extra holds response fields not modelled by QueryResponse. They are encoded back as is.
+		Extra map[string]json.RawMessage `json:"-"`
 	}
 
 	QueryResult struct {
-		Source           string                 `json:"source"`
-		ResolvedQuery    string                 `json:"resolvedQuery"`
-		Action           string                 `json:"action"`
-		ActionIncomplete bool                   `json:"actionIncomplete"`
-		Parameters       map[string]interface{} `json:"parameters"`
-		Contexts         []DialogContext        `json:"contexts"`
-		Fulfillment      Fulfillment            `json:"fulfillment"`
-		Metadata         Metadata               `json:"metadata"`
+		Source           string          `json:"source"`
+		ResolvedQuery    string          `json:"resolvedQuery"`
+		Action           string          `json:"action"`
+		ActionIncomplete bool            `json:"actionIncomplete"`
+		Parameters       Parameters      `json:"parameters"`
+		Contexts         []DialogContext `json:"contexts"`
+		Fulfillment      Fulfillment     `json:"fulfillment"`
+		Metadata         Metadata        `json:"metadata"`
+		//Score is the intent matching confidence from 0 to 1.
+		Score float64 `json:"score"`
+		//Extra holds result fields not modelled by QueryResult. They are encoded back as is.
+		Extra map[string]json.RawMessage `json:"-"`
 	}
 
 	Fulfillment struct {
-		Speech      string     `json:"speech"`
-		DisplayText string     `json:"displayText"`
-		Source      string     `json:"source"`
-		Data        string     `json:"data"`
-		Messages    []Messages `json:"messages"`
+		Speech      string `json:"speech"`
+		DisplayText string `json:"displayText"`
+		Source      string `json:"source"`
+		//Data is an arbitrary JSON object passed by the webhook, see DecodeData.
+		Data     json.RawMessage `json:"data,omitempty"`
+		Messages Messages        `json:"messages"`
+		//Extra holds fulfillment fields not modelled by Fulfillment. They are encoded back as is.
+		Extra map[string]json.RawMessage `json:"-"`
 	}
 
 	Metadata struct {
@@ -146,10 +165,82 @@ type (
 		ErrorDetails string `json:"errorDetails"`
 	}
 
-	//Messages is text response message object
-	Messages struct {
-		Type   int    `json:"type"`
-		Speech string `json:"speech"`
+	//Intent is a mapping between what a user says and what action should be taken by the agent.
+	Intent struct {
+		ID   string `json:"id,omitempty"`
+		Name string `json:"name"`
+		//Auto enables machine learning for the intent.
+		Auto bool `json:"auto"`
+		//Contexts are input context names required to match the intent.
+		Contexts  []string         `json:"contexts,omitempty"`
+		Templates []string         `json:"templates,omitempty"`
+		UserSays  []UserSays       `json:"userSays,omitempty"`
+		Responses []IntentResponse `json:"responses,omitempty"`
+		Priority  int              `json:"priority"`
+		Events    []Event          `json:"events,omitempty"`
+		//WebhookUsed enables webhook fulfillment of the intent.
+		WebhookUsed bool `json:"webhookUsed"`
+		//WebhookForSlotFilling enables webhook calls while required parameters are being filled.
+		WebhookForSlotFilling bool `json:"webhookForSlotFilling"`
+		FallbackIntent        bool `json:"fallbackIntent"`
+		//Extra holds intent fields not modelled by Intent, e.g. "lastUpdate". They are encoded back as is,
+		//so a fetched intent can be updated without losing them.
+		Extra map[string]json.RawMessage `json:"-"`
+	}
+
+	//UserSays is an example of what a user may say to trigger the intent.
+	UserSays struct {
+		ID         string         `json:"id,omitempty"`
+		Data       []UserSaysPart `json:"data"`
+		IsTemplate bool           `json:"isTemplate"`
+		Count      int            `json:"count"`
+	}
+
+	//UserSaysPart is a piece of the example text. Parts annotated with an entity have Meta set to the
+	//entity name, e.g. "@sys.date", and Alias set to the parameter name.
+	UserSaysPart struct {
+		Text        string `json:"text"`
+		Meta        string `json:"meta,omitempty"`
+		Alias       string `json:"alias,omitempty"`
+		UserDefined bool   `json:"userDefined,omitempty"`
+	}
+
+	//IntentResponse describes what the agent does when the intent is matched.
+	IntentResponse struct {
+		Action           string            `json:"action,omitempty"`
+		ResetContexts    bool              `json:"resetContexts"`
+		AffectedContexts []DialogContext   `json:"affectedContexts,omitempty"`
+		Parameters       []IntentParameter `json:"parameters,omitempty"`
+		Messages         Messages          `json:"messages,omitempty"`
+		//Extra holds response fields not modelled by IntentResponse, e.g. "speech". They are encoded back as is.
+		Extra map[string]json.RawMessage `json:"-"`
+	}
+
+	//IntentParameter is extracted from what a user says and passed to QueryResult.Parameters.
+	IntentParameter struct {
+		ID   string `json:"id,omitempty"`
+		Name string `json:"name"`
+		//Value is a reference to the value, e.g. "$city".
+		Value string `json:"value"`
+		//DataType is the entity name, e.g. "@sys.geo-city".
+		DataType     string   `json:"dataType"`
+		DefaultValue string   `json:"defaultValue,omitempty"`
+		Required     bool     `json:"required"`
+		Prompts      []string `json:"prompts,omitempty"`
+		IsList       bool     `json:"isList"`
+	}
+
+	//IntentDescription is an item of the agent intents list.
+	IntentDescription struct {
+		ID             string            `json:"id"`
+		Name           string            `json:"name"`
+		ContextIn      []string          `json:"contextIn"`
+		ContextOut     []DialogContext   `json:"contextOut"`
+		Events         []Event           `json:"events"`
+		Parameters     []IntentParameter `json:"parameters"`
+		Actions        []string          `json:"actions"`
+		Priority       int               `json:"priority"`
+		FallbackIntent bool              `json:"fallbackIntent"`
 	}
 
 	SupportedLang string
@@ -157,14 +248,81 @@ type (
 	//QueryAPIEndpoint is used to process natural language in the form of text. The query requests return structured data in JSON format with an action and parameters for that action.
 	QueryAPIEndpoint interface {
 		DoQuery(q Query) (*QueryResponse, error)
+		DoQueryContext(ctx context.Context, q Query) (*QueryResponse, error)
 		TextRequest(sessionID string, text string) (*QueryResponse, error)
+		TextRequestContext(ctx context.Context, sessionID string, text string) (*QueryResponse, error)
+		EventRequest(sessionID string, name string, data interface{}) (*QueryResponse, error)
+		EventRequestContext(ctx context.Context, sessionID string, name string, data interface{}) (*QueryResponse, error)
 	}
 
 	SpeechHandler func(io.Reader)error
 
+	//ContextsAPIEndpoint is used to manage contexts of a session. Variants taking context.Context are
+	//suffixed WithContext instead of Context, so e.g. DeleteWithContext is not read as deleting a dialog context.
+	ContextsAPIEndpoint interface {
+		List(sessionID string) ([]DialogContext, error)
+		ListWithContext(ctx context.Context, sessionID string) ([]DialogContext, error)
+		Get(sessionID string, name string) (*DialogContext, error)
+		GetWithContext(ctx context.Context, sessionID string, name string) (*DialogContext, error)
+		Add(sessionID string, contexts ...DialogContext) ([]string, error)
+		AddWithContext(ctx context.Context, sessionID string, contexts ...DialogContext) ([]string, error)
+		Delete(sessionID string, name string) error
+		DeleteWithContext(ctx context.Context, sessionID string, name string) error
+		DeleteAll(sessionID string) error
+		DeleteAllWithContext(ctx context.Context, sessionID string) error
+	}
+
+	//UserEntitiesAPIEndpoint is used to manage entities that exist only within a session.
+	UserEntitiesAPIEndpoint interface {
+		Create(sessionID string, entities ...Entity) error
+		CreateContext(ctx context.Context, sessionID string, entities ...Entity) error
+		Get(sessionID string, name string) (*Entity, error)
+		GetContext(ctx context.Context, sessionID string, name string) (*Entity, error)
+		Update(sessionID string, entity Entity) error
+		UpdateContext(ctx context.Context, sessionID string, entity Entity) error
+		Delete(sessionID string, name string) error
+		DeleteContext(ctx context.Context, sessionID string, name string) error
+	}
+
+	//EntitiesAPIEndpoint is used to manage developer entities of the agent.
+	//Entities are identified by either ID or name.
+	EntitiesAPIEndpoint interface {
+		List() ([]EntityDescription, error)
+		ListContext(ctx context.Context) ([]EntityDescription, error)
+		Get(eid string) (*Entity, error)
+		GetContext(ctx context.Context, eid string) (*Entity, error)
+		Create(entity Entity) (string, error)
+		CreateContext(ctx context.Context, entity Entity) (string, error)
+		Update(eid string, entity Entity) error
+		UpdateContext(ctx context.Context, eid string, entity Entity) error
+		Delete(eid string) error
+		DeleteContext(ctx context.Context, eid string) error
+		AddEntries(eid string, entries ...EntityEntry) error
+		AddEntriesContext(ctx context.Context, eid string, entries ...EntityEntry) error
+		UpdateEntries(eid string, entries ...EntityEntry) error
+		UpdateEntriesContext(ctx context.Context, eid string, entries ...EntityEntry) error
+		DeleteEntries(eid string, values ...string) error
+		DeleteEntriesContext(ctx context.Context, eid string, values ...string) error
+	}
+
+	//IntentsAPIEndpoint is used to manage intents of the agent.
+	IntentsAPIEndpoint interface {
+		List() ([]IntentDescription, error)
+		ListContext(ctx context.Context) ([]IntentDescription, error)
+		Get(iid string) (*Intent, error)
+		GetContext(ctx context.Context, iid string) (*Intent, error)
+		Create(intent Intent) (string, error)
+		CreateContext(ctx context.Context, intent Intent) (string, error)
+		Update(iid string, intent Intent) error
+		UpdateContext(ctx context.Context, iid string, intent Intent) error
+		Delete(iid string) error
+		DeleteContext(ctx context.Context, iid string) error
+	}
+
 	//TtsAPIEndpoint is used to perform text-to-speech – generate speech (audio file) from text.
 	TtsAPIEndpoint interface {
 		DoTts(text string, handler SpeechHandler) error
+		DoTtsContext(ctx context.Context, text string, handler SpeechHandler) error
 	}
 )
 
@@ -216,6 +374,69 @@ func (response *QueryResponse) Decode(data []byte) (err error) {
 	return
 }
 
+func (response *QueryResponse) UnmarshalJSON(data []byte) (err error) {
+	type queryResponse QueryResponse
+	response.Extra, err = unmarshalWithExtra(data, (*queryResponse)(response))
+	return
+}
+
+func (response QueryResponse) MarshalJSON() ([]byte, error) {
+	type queryResponse QueryResponse
+	return marshalWithExtra(queryResponse(response), response.Extra)
+}
+
+func (result *QueryResult) UnmarshalJSON(data []byte) (err error) {
+	type queryResult QueryResult
+	result.Extra, err = unmarshalWithExtra(data, (*queryResult)(result))
+	return
+}
+
+func (result QueryResult) MarshalJSON() ([]byte, error) {
+	type queryResult QueryResult
+	return marshalWithExtra(queryResult(result), result.Extra)
+}
+
+func (f *Fulfillment) UnmarshalJSON(data []byte) (err error) {
+	type fulfillment Fulfillment
+	f.Extra, err = unmarshalWithExtra(data, (*fulfillment)(f))
+	return
+}
+
+func (f Fulfillment) MarshalJSON() ([]byte, error) {
+	type fulfillment Fulfillment
+	return marshalWithExtra(fulfillment(f), f.Extra)
+}
+
+func (intent *Intent) UnmarshalJSON(data []byte) (err error) {
+	type intentFields Intent
+	intent.Extra, err = unmarshalWithExtra(data, (*intentFields)(intent))
+	return
+}
+
+func (intent Intent) MarshalJSON() ([]byte, error) {
+	type intentFields Intent
+	return marshalWithExtra(intentFields(intent), intent.Extra)
+}
+
+func (response *IntentResponse) UnmarshalJSON(data []byte) (err error) {
+	type intentResponse IntentResponse
+	response.Extra, err = unmarshalWithExtra(data, (*intentResponse)(response))
+	return
+}
+
+func (response IntentResponse) MarshalJSON() ([]byte, error) {
+	type intentResponse IntentResponse
+	return marshalWithExtra(intentResponse(response), response.Extra)
+}
+
+//DecodeData decodes webhook data into v. It does nothing when there is no data.
+func (f *Fulfillment) DecodeData(v interface{}) error {
+	if len(f.Data) == 0 || string(f.Data) == "null" {
+		return nil
+	}
+	return json.Unmarshal(f.Data, v)
+}
+
 func IsLanguageSupport(lang string) (bool, SupportedLang) {
 	for _, l := range SupportedLanguages {
 		if l == SupportedLang(lang) {
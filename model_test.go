@@ -26,6 +26,7 @@ import (
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+	"encoding/json"
 )
 
 var _ = Describe("Model", func() {
@@ -80,6 +81,142 @@ var _ = Describe("Model", func() {
 
 		Ω(response.Result.Fulfillment.Messages).Should(HaveLen(1))
 
-		Ω(response.Result.Fulfillment.Messages[0].Speech).Should(Equal("Message speech text"))
+		Ω(response.Result.Fulfillment.TextMessages()[0].Text()).Should(Equal("Message speech text"))
+		Ω(response.Result.Score).Should(Equal(0.69))
+	})
+
+	It("Should keep webhook data and unknown fields", func() {
+		resultStr := `{
+			"id": "5bb49696-549d-4655-bfb1-21e1dc806379",
+			"timestamp": "2016-12-30T14:29:02.746Z",
+			"lang": "en",
+			"result": {
+				"source": "agent",
+				"resolvedQuery": "Some query",
+				"action": "ActionName",
+				"actionIncomplete": false,
+				"parameters": {},
+				"contexts": [],
+				"metadata": {},
+				"fulfillment": {
+					"speech": "Some speech text",
+					"data": {"google": {"expect_user_response": true}},
+					"messages": [],
+					"newFulfillmentField": "x"
+				},
+				"score": 1,
+				"newResultField": {"nested": [1, 2]}
+			},
+			"status": {"code": 200, "errorType": "success"},
+			"sessionId": "1"
+		}`
+		response := &QueryResponse{}
+		Ω(response.Decode([]byte(resultStr))).Should(Succeed())
+
+		Ω(response.Extra).Should(HaveKey("lang"))
+		Ω(response.Result.Extra["newResultField"]).Should(MatchJSON(`{"nested": [1, 2]}`))
+		Ω(response.Result.Fulfillment.Extra["newFulfillmentField"]).Should(MatchJSON(`"x"`))
+
+		var data struct {
+			Google struct {
+				ExpectUserResponse bool `json:"expect_user_response"`
+			} `json:"google"`
+		}
+		Ω(response.Result.Fulfillment.DecodeData(&data)).Should(Succeed())
+		Ω(data.Google.ExpectUserResponse).Should(BeTrue())
+
+		out, err := json.Marshal(response)
+		Ω(err).ShouldNot(HaveOccurred())
+		var fields map[string]interface{}
+		Ω(json.Unmarshal(out, &fields)).Should(Succeed())
+		Ω(fields).Should(HaveKeyWithValue("lang", "en"))
+		Ω(fields["result"]).Should(HaveKeyWithValue("newResultField", HaveKey("nested")))
+		Ω(fields["result"]).Should(HaveKeyWithValue("fulfillment", HaveKeyWithValue("newFulfillmentField", "x")))
+		Ω(fields["result"]).Should(HaveKeyWithValue("fulfillment", HaveKey("data")))
+	})
+
+	Describe("Rich messages", func() {
+		messagesStr := `[
+			{"type": 0, "speech": "Default speech"},
+			{"type": 0, "platform": "telegram", "speech": ["Hi", "Hello"]},
+			{"type": 1, "platform": "facebook", "title": "Card", "subtitle": "Sub", "imageUrl": "https://example.com/card.png",
+				"buttons": [{"text": "Open", "postback": "https://example.com"}]},
+			{"type": 2, "platform": "facebook", "title": "Pick one", "replies": ["Yes", "No"]},
+			{"type": 3, "imageUrl": "https://example.com/image.png"},
+			{"type": 4, "payload": {"custom": {"nested": [1, 2]}}},
+			{"type": "simple_response", "platform": "google", "textToSpeech": "Hello", "displayText": "Hello!"},
+			{"type": "basic_card", "platform": "google", "title": "Basic", "formattedText": "Text"}
+		]`
+
+		It("Should decode messages by type", func() {
+			f := Fulfillment{}
+			err := json.Unmarshal([]byte(`{"speech": "Default speech", "messages": `+messagesStr+`}`), &f)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(f.Messages).Should(HaveLen(8))
+
+			Ω(f.TextMessages()).Should(HaveLen(2))
+			Ω(f.TextMessages()[1].Platform).Should(Equal("telegram"))
+			Ω(f.TextMessages()[1].Speech).Should(Equal([]string{"Hi", "Hello"}))
+
+			Ω(f.Cards()).Should(HaveLen(1))
+			Ω(f.Cards()[0].Buttons).Should(Equal([]CardButton{{Text: "Open", Postback: "https://example.com"}}))
+			Ω(f.QuickReplies()[0].Replies).Should(Equal([]string{"Yes", "No"}))
+			Ω(f.Images()[0].ImageURL).Should(Equal("https://example.com/image.png"))
+			Ω(f.CustomPayloads()[0].Payload).Should(MatchJSON(`{"custom": {"nested": [1, 2]}}`))
+			Ω(f.SimpleResponses()[0].DisplayText).Should(Equal("Hello!"))
+
+			unknown, ok := f.Messages[7].(*UnknownMessage)
+			Ω(ok).Should(BeTrue())
+			Ω(unknown.MessageType()).Should(Equal(MessageType("basic_card")))
+			Ω(unknown.MessagePlatform()).Should(Equal("google"))
+
+			Ω(f.Messages.ForPlatform("facebook")).Should(HaveLen(2))
+			Ω(f.Messages.ForPlatform("")).Should(HaveLen(3))
+		})
+
+		It("Should encode messages without loss", func() {
+			var messages Messages
+			Ω(json.Unmarshal([]byte(messagesStr), &messages)).Should(Succeed())
+			out, err := json.Marshal(messages)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(out).Should(MatchJSON(messagesStr))
+		})
+
+		It("Should keep unmodelled message fields", func() {
+			withExtra := `[
+				{"type": 0, "lang": "en", "speech": "hi"},
+				{"type": 1, "title": "t", "id": "x", "buttons": [{"text": "Open"}]},
+				{"type": 2, "replies": ["Yes"], "lang": "en"},
+				{"type": 3, "imageUrl": "https://example.com/image.png", "accessibilityText": "image"},
+				{"type": 4, "payload": {}, "lang": "en"},
+				{"type": "simple_response", "platform": "google", "displayText": "Hi", "lang": "en"}
+			]`
+			var messages Messages
+			Ω(json.Unmarshal([]byte(withExtra), &messages)).Should(Succeed())
+			Ω(messages[0].(*TextMessage).Extra).Should(Equal(map[string]json.RawMessage{"lang": json.RawMessage(`"en"`)}))
+			out, err := json.Marshal(messages)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(out).Should(MatchJSON(withExtra))
+		})
+
+		It("Should encode constructed messages", func() {
+			messages := Messages{
+				NewTextMessage("Hello"),
+				&QuickRepliesMessage{Replies: []string{"Yes"}},
+				&UnknownMessage{Type: "basic_card", Platform: "google"},
+			}
+			out, err := json.Marshal(messages)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(out).Should(MatchJSON(`[
+				{"type": 0, "speech": "Hello"},
+				{"type": 2, "replies": ["Yes"]},
+				{"type": "basic_card", "platform": "google"}
+			]`))
+		})
+
+		It("Should skip nil messages when filtering by platform", func() {
+			messages := Messages{nil, NewTextMessage("Hello")}
+			Ω(messages.ForPlatform("")).Should(HaveLen(1))
+		})
 	})
 })
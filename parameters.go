@@ -0,0 +1,264 @@
+package gapiai
+
+/***********************************************************************************************************************
+ *
+ * Go client-side library for API.AI
+ * =================================================
+ *
+ * Copyright (C) 2017 by Slava Vasylyev
+ *
+ *
+ * *********************************************************************************************************************
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance with
+ * the License. You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ *
+ ***********************************************************************************************************************/
+
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type (
+	//Parameters are values extracted from a user query, keyed by parameter name. Values keep the
+	//shape API.AI sends them in: strings for most system entities, numbers, lists, and objects for
+	//composite entities like @sys.unit-currency or @sys.duration.
+	Parameters map[string]interface{}
+
+	//UnitCurrency is the value of @sys.unit-currency.
+	UnitCurrency struct {
+		Amount   float64 `json:"amount"`
+		Currency string  `json:"currency"`
+	}
+)
+
+var (
+	//ErrParameterNotFound is returned by accessors when there is no parameter with the name.
+	ErrParameterNotFound = errors.New("gapiai: parameter not found")
+	//ErrParameterType is returned by accessors when the value can not be converted to the requested type.
+	ErrParameterType = errors.New("gapiai: unexpected parameter type")
+)
+
+//layouts of @sys.date-time, @sys.date and @sys.time values
+var timeLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02",
+	"15:04:05",
+}
+
+//durationUnits maps @sys.duration units of fixed length
+var durationUnits = map[string]time.Duration{
+	"ms":  time.Millisecond,
+	"s":   time.Second,
+	"min": time.Minute,
+	"h":   time.Hour,
+	"day": 24 * time.Hour,
+	"wk":  7 * 24 * time.Hour,
+}
+
+func typeError(name string, want string, value interface{}) error {
+	return fmt.Errorf("%w: parameter %q is %T, want %s", ErrParameterType, name, value, want)
+}
+
+//Has reports whether the parameter is present, even if its value is empty.
+func (p Parameters) Has(name string) bool {
+	_, ok := p[name]
+	return ok
+}
+
+func (p Parameters) get(name string) (interface{}, error) {
+	value, ok := p[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrParameterNotFound, name)
+	}
+	return value, nil
+}
+
+//GetString returns the string value. Numbers and booleans are formatted.
+func (p Parameters) GetString(name string) (string, error) {
+	value, err := p.get(name)
+	if err != nil {
+		return "", err
+	}
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	}
+	return "", typeError(name, "string", value)
+}
+
+//GetFloat returns the number value. Numbers sent as strings, as @sys.number does, are parsed.
+func (p Parameters) GetFloat(name string) (float64, error) {
+	value, err := p.get(name)
+	if err != nil {
+		return 0, err
+	}
+	return toFloat(name, value)
+}
+
+func toFloat(name string, value interface{}) (float64, error) {
+	switch v := value.(type) {
+	case float64:
+		return v, nil
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		if err != nil {
+			return 0, typeError(name, "number", value)
+		}
+		return f, nil
+	}
+	return 0, typeError(name, "number", value)
+}
+
+//GetInt returns the number value if it is integral.
+func (p Parameters) GetInt(name string) (int, error) {
+	f, err := p.GetFloat(name)
+	if err != nil {
+		return 0, err
+	}
+	if f != float64(int(f)) {
+		return 0, fmt.Errorf("%w: parameter %q is %v, want integer", ErrParameterType, name, f)
+	}
+	return int(f), nil
+}
+
+//GetStrings returns the list value. A single string is returned as a list of one element.
+func (p Parameters) GetStrings(name string) ([]string, error) {
+	value, err := p.get(name)
+	if err != nil {
+		return nil, err
+	}
+	switch v := value.(type) {
+	case string:
+		return []string{v}, nil
+	case []interface{}:
+		result := make([]string, 0, len(v))
+		for _, item := range v {
+			s, ok := item.(string)
+			if !ok {
+				return nil, typeError(name, "list of strings", value)
+			}
+			result = append(result, s)
+		}
+		return result, nil
+	}
+	return nil, typeError(name, "list of strings", value)
+}
+
+//GetParameters returns the value of a composite entity as Parameters.
+func (p Parameters) GetParameters(name string) (Parameters, error) {
+	value, err := p.get(name)
+	if err != nil {
+		return nil, err
+	}
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return Parameters(v), nil
+	case Parameters:
+		return v, nil
+	}
+	return nil, typeError(name, "object", value)
+}
+
+//GetTime parses @sys.date, @sys.time and @sys.date-time values. Values without time zone are in UTC,
+//@sys.time values have zero date.
+func (p Parameters) GetTime(name string) (time.Time, error) {
+	s, err := p.GetString(name)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return parseTime(name, s)
+}
+
+func parseTime(name string, s string) (time.Time, error) {
+	for _, layout := range timeLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("%w: parameter %q value %q is not a date or time", ErrParameterType, name, s)
+}
+
+//GetPeriod parses @sys.date-period and @sys.time-period values, e.g. "2017-01-01/2017-01-31".
+func (p Parameters) GetPeriod(name string) (start time.Time, end time.Time, err error) {
+	s, err := p.GetString(name)
+	if err != nil {
+		return
+	}
+	bounds := strings.Split(s, "/")
+	if len(bounds) != 2 {
+		err = fmt.Errorf("%w: parameter %q value %q is not a period", ErrParameterType, name, s)
+		return
+	}
+	if start, err = parseTime(name, bounds[0]); err != nil {
+		return
+	}
+	end, err = parseTime(name, bounds[1])
+	return
+}
+
+//GetDuration converts @sys.duration value, e.g. {"amount": 10, "unit": "min"}. Months and years have
+//no fixed length and are reported as ErrParameterType.
+func (p Parameters) GetDuration(name string) (time.Duration, error) {
+	amount, unit, err := p.getAmount(name, "unit")
+	if err != nil {
+		return 0, err
+	}
+	d, ok := durationUnits[unit]
+	if !ok {
+		return 0, fmt.Errorf("%w: parameter %q has unsupported duration unit %q", ErrParameterType, name, unit)
+	}
+	return time.Duration(amount * float64(d)), nil
+}
+
+//GetUnitCurrency converts @sys.unit-currency value, e.g. {"amount": 10, "currency": "USD"}.
+func (p Parameters) GetUnitCurrency(name string) (UnitCurrency, error) {
+	amount, currency, err := p.getAmount(name, "currency")
+	if err != nil {
+		return UnitCurrency{}, err
+	}
+	return UnitCurrency{Amount: amount, Currency: currency}, nil
+}
+
+//getAmount reads composite values with "amount" and unit fields.
+func (p Parameters) getAmount(name string, unitField string) (float64, string, error) {
+	obj, err := p.GetParameters(name)
+	if err != nil {
+		return 0, "", err
+	}
+	amount, err := obj.GetFloat("amount")
+	if err != nil {
+		return 0, "", typeError(name, "object with amount", p[name])
+	}
+	unit, err := obj.GetString(unitField)
+	if err != nil {
+		return 0, "", typeError(name, "object with "+unitField, p[name])
+	}
+	return amount, unit, nil
+}
+
+//Decode stores parameters into v using encoding/json rules and struct tags.
+func (p Parameters) Decode(v interface{}) error {
+	data, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
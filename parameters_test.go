@@ -0,0 +1,195 @@
+package gapiai_test
+
+/***********************************************************************************************************************
+ *
+ * Go client-side library for API.AI
+ * =================================================
+ *
+ * Copyright (C) 2017 by Slava Vasylyev
+ *
+ *
+ * *********************************************************************************************************************
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance with
+ * the License. You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ *
+ ***********************************************************************************************************************/
+
+
+import (
+	. "github.com/slavaVA/go-api.ai"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+var _ = Describe("Parameters", func() {
+	var params Parameters
+
+	BeforeEach(func() {
+		params = Parameters{}
+		err := json.Unmarshal([]byte(`{
+			"city": "Kiev",
+			"empty": "",
+			"count": 3,
+			"number": "2.5",
+			"colors": ["red", "green"],
+			"date": "2017-01-02",
+			"time": "14:30:00",
+			"date-time": "2017-01-02T14:30:00Z",
+			"date-period": "2017-01-01/2017-01-31",
+			"time-period": "14:00:00/15:30:00",
+			"duration": {"amount": 10, "unit": "min"},
+			"months": {"amount": 2, "unit": "mo"},
+			"price": {"amount": "9.99", "currency": "USD"},
+			"address": {"city": "Kiev", "zip": "01001"}
+		}`), &params)
+		Ω(err).ShouldNot(HaveOccurred())
+	})
+
+	It("Should return strings and numbers", func() {
+		Ω(params.GetString("city")).Should(Equal("Kiev"))
+		Ω(params.GetString("empty")).Should(Equal(""))
+		Ω(params.GetString("count")).Should(Equal("3"))
+		Ω(params.GetInt("count")).Should(Equal(3))
+		Ω(params.GetFloat("number")).Should(Equal(2.5))
+		Ω(params.GetStrings("colors")).Should(Equal([]string{"red", "green"}))
+		Ω(params.GetStrings("city")).Should(Equal([]string{"Kiev"}))
+	})
+
+	It("Should report missing and mistyped parameters", func() {
+		Ω(params.Has("empty")).Should(BeTrue())
+		_, err := params.GetString("missing")
+		Ω(errors.Is(err, ErrParameterNotFound)).Should(BeTrue())
+		_, err = params.GetInt("number")
+		Ω(errors.Is(err, ErrParameterType)).Should(BeTrue())
+		_, err = params.GetFloat("city")
+		Ω(errors.Is(err, ErrParameterType)).Should(BeTrue())
+		_, err = params.GetStrings("address")
+		Ω(errors.Is(err, ErrParameterType)).Should(BeTrue())
+	})
+
+	It("Should parse dates, times and periods", func() {
+		Ω(params.GetTime("date")).Should(Equal(time.Date(2017, 1, 2, 0, 0, 0, 0, time.UTC)))
+		Ω(params.GetTime("date-time")).Should(BeTemporally("==", time.Date(2017, 1, 2, 14, 30, 0, 0, time.UTC)))
+		t, err := params.GetTime("time")
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(t.Hour()).Should(Equal(14))
+		Ω(t.Minute()).Should(Equal(30))
+
+		start, end, err := params.GetPeriod("date-period")
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(start).Should(Equal(time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC)))
+		Ω(end).Should(Equal(time.Date(2017, 1, 31, 0, 0, 0, 0, time.UTC)))
+
+		start, end, err = params.GetPeriod("time-period")
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(end.Sub(start)).Should(Equal(90 * time.Minute))
+
+		_, err = params.GetTime("city")
+		Ω(errors.Is(err, ErrParameterType)).Should(BeTrue())
+	})
+
+	It("Should convert durations and amounts", func() {
+		Ω(params.GetDuration("duration")).Should(Equal(10 * time.Minute))
+		_, err := params.GetDuration("months")
+		Ω(errors.Is(err, ErrParameterType)).Should(BeTrue())
+		Ω(params.GetUnitCurrency("price")).Should(Equal(UnitCurrency{Amount: 9.99, Currency: "USD"}))
+	})
+
+	It("Should return composite values and decode into struct", func() {
+		address, err := params.GetParameters("address")
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(address.GetString("zip")).Should(Equal("01001"))
+
+		var v struct {
+			City    string       `json:"city"`
+			Colors  []string     `json:"colors"`
+			Price   UnitCurrency `json:"price"`
+			Address struct {
+				Zip string `json:"zip"`
+			} `json:"address"`
+		}
+		params["price"] = map[string]interface{}{"amount": 9.99, "currency": "USD"}
+		Ω(params.Decode(&v)).Should(Succeed())
+		Ω(v.City).Should(Equal("Kiev"))
+		Ω(v.Colors).Should(Equal([]string{"red", "green"}))
+		Ω(v.Price).Should(Equal(UnitCurrency{Amount: 9.99, Currency: "USD"}))
+		Ω(v.Address.Zip).Should(Equal("01001"))
+	})
+
+	Describe("Bind", func() {
+		type Address struct {
+			City string `apiai:"city,required"`
+			Zip  int    `apiai:"zip"`
+		}
+
+		It("Should bind parameters into struct fields", func() {
+			var v struct {
+				City     string
+				Count    uint8         `apiai:"count"`
+				Number   *float64      `apiai:"number"`
+				Colors   []string      `apiai:"colors"`
+				Date     time.Time     `apiai:"date"`
+				Dates    []time.Time   `apiai:"dates"`
+				Duration time.Duration `apiai:"duration"`
+				Price    UnitCurrency  `apiai:"price"`
+				Address  Address       `apiai:"address"`
+				Empty    string        `apiai:"empty"`
+				Raw      interface{}   `apiai:"raw"`
+				Ignored  string        `apiai:"-"`
+			}
+			params["dates"] = []interface{}{"2017-01-02", "2017-01-03"}
+			params["raw"] = map[string]interface{}{"a": "b"}
+			result := QueryResult{Parameters: params}
+			Ω(result.BindParameters(&v)).Should(Succeed())
+
+			Ω(v.City).Should(Equal("Kiev"))
+			Ω(v.Count).Should(Equal(uint8(3)))
+			Ω(*v.Number).Should(Equal(2.5))
+			Ω(v.Colors).Should(Equal([]string{"red", "green"}))
+			Ω(v.Date).Should(Equal(time.Date(2017, 1, 2, 0, 0, 0, 0, time.UTC)))
+			Ω(v.Dates).Should(HaveLen(2))
+			Ω(v.Dates[1].Day()).Should(Equal(3))
+			Ω(v.Duration).Should(Equal(10 * time.Minute))
+			Ω(v.Price).Should(Equal(UnitCurrency{Amount: 9.99, Currency: "USD"}))
+			Ω(v.Address).Should(Equal(Address{City: "Kiev", Zip: 1001}))
+			Ω(v.Raw).Should(Equal(map[string]interface{}{"a": "b"}))
+			Ω(v.Ignored).Should(BeEmpty())
+		})
+
+		It("Should report missing required parameters", func() {
+			var v struct {
+				City    string  `apiai:"city,required"`
+				Empty   string  `apiai:"empty,required"`
+				Missing int     `apiai:"missing,required"`
+				Address Address `apiai:"address"`
+			}
+			params["address"] = map[string]interface{}{"zip": "01001"}
+			err := params.Bind(&v)
+
+			var missingErr *MissingParametersError
+			Ω(errors.As(err, &missingErr)).Should(BeTrue())
+			Ω(missingErr.Names).Should(Equal([]string{"empty", "missing", "address.city"}))
+			Ω(v.City).Should(Equal("Kiev"))
+			Ω(v.Address.Zip).Should(Equal(1001))
+		})
+
+		It("Should fail on values not convertible to field type", func() {
+			var v struct {
+				Count int8 `apiai:"city"`
+			}
+			Ω(errors.Is(params.Bind(&v), ErrParameterType)).Should(BeTrue())
+			Ω(params.Bind(v)).Should(HaveOccurred())
+		})
+	})
+})
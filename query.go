@@ -22,20 +22,34 @@ package gapiai
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
-	"io/ioutil"
 	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
 )
 
 type (
 	QueryService struct {
 		ApiService
+		//UseGET makes DoQuery send the query as URL parameters of a GET request instead of a POST body.
+		//Only fields that API.AI accepts in URL parameters may be set, see ErrNotEncodableAsGET.
+		UseGET   bool
 		queryURL string
 	}
 )
 
+//ErrUnsupportedLanguage is returned when the query language is not one of SupportedLanguages.
+var ErrUnsupportedLanguage = fmt.Errorf("%w: unsupported language", ErrInvalidQuery)
+
+//ErrNotEncodableAsGET is returned when the query has fields that cannot be passed as GET parameters:
+//several query texts, confidence, entities, event data or contexts with parameters or lifespan.
+var ErrNotEncodableAsGET = errors.New("gapiai: query can not be sent with GET")
+
 func NewQueryAPIEndpoint(url string, version string, cfg *ApiConfig) *QueryService {
 	svc := &QueryService{
 		ApiService: ApiService{
@@ -52,17 +66,109 @@ func DefaultQueryAPIEndpoint(cfg *ApiConfig) *QueryService {
 }
 
 func (service *QueryService) TextRequest(sessionID string, text string) (*QueryResponse, error) {
+	return service.TextRequestContext(context.Background(), sessionID, text)
+}
+
+func (service *QueryService) TextRequestContext(ctx context.Context, sessionID string, text string) (*QueryResponse, error) {
 	q := Query{
 		Query:     []string{text},
 		SessionID: sessionID,
 	}
-	return service.DoQuery(q)
+	return service.DoQueryContext(ctx, q)
 }
 
 func (service *QueryService) DoQuery(q Query) (*QueryResponse, error) {
+	return service.DoQueryContext(context.Background(), q)
+}
+
+//DoQueryContext sends the query bound to ctx. Cancellation or deadline of ctx aborts the request and
+//the context error is returned. The query must have either query text or an event, see ErrInvalidQuery.
+func (service *QueryService) DoQueryContext(ctx context.Context, q Query) (*QueryResponse, error) {
+	if err := q.validate(); err != nil {
+		return nil, err
+	}
+	if err := service.applyDefaults(&q); err != nil {
+		return nil, err
+	}
+	if err := checkCapability(SupportedLang(q.Lang), TextQuery); err != nil {
+		return nil, err
+	}
+
+	var req *http.Request
+	var err error
+	if service.UseGET {
+		req, err = service.newGetRequest(q)
+	} else {
+		req, err = service.newPostRequest(q)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Authorization", "Bearer "+service.Config.AccessToken)
+
+	return service.query(ctx, req)
+}
+
+//applyDefaults fills language, timezone and location the query does not set from the config
+//and validates them.
+func (service *QueryService) applyDefaults(q *Query) error {
+	if q.Lang == "" {
+		q.Lang = string(service.Config.Lang)
+	}
+	if q.Timezone == "" {
+		q.Timezone = service.Config.Timezone
+	}
+	if q.Location == nil && service.Config.Location != nil {
+		location := *service.Config.Location
+		q.Location = &location
+	}
+
+	if q.Lang != "" {
+		if ok, _ := IsLanguageSupport(q.Lang); !ok {
+			return fmt.Errorf("%w %q", ErrUnsupportedLanguage, q.Lang)
+		}
+	}
+	if q.Timezone != "" && !validTimezone(q.Timezone) {
+		return fmt.Errorf("%w: unknown timezone %q", ErrInvalidQuery, q.Timezone)
+	}
+	if l := q.Location; l != nil {
+		if !(l.Latitude >= -90 && l.Latitude <= 90) || !(l.Longitude >= -180 && l.Longitude <= 180) {
+			return fmt.Errorf("%w: location %v,%v is out of range", ErrInvalidQuery, l.Latitude, l.Longitude)
+		}
+	}
+	return nil
+}
+
+var (
+	//timezones caches names of zones found in the zone database
+	timezones     sync.Map
+	tzdataOnce    sync.Once
+	tzdataPresent bool
+)
 
-	q.Lang = string(service.Config.Lang)
+//validTimezone reports whether name is an IANA time zone. Loaded zones are cached, as loading reads
+//the zone database. When the host has no zone database, every name but "Local" is accepted and left
+//for API.AI to validate.
+func validTimezone(name string) bool {
+	if name == "Local" {
+		return false
+	}
+	if _, ok := timezones.Load(name); ok {
+		return true
+	}
+	if _, err := time.LoadLocation(name); err != nil {
+		tzdataOnce.Do(func() {
+			_, err := time.LoadLocation("America/New_York")
+			tzdataPresent = err == nil
+		})
+		return !tzdataPresent
+	}
+	timezones.Store(name, struct{}{})
+	return true
+}
 
+func (service *QueryService) newPostRequest(q Query) (*http.Request, error) {
 	jsonStr, err := json.Marshal(q)
 	if err != nil {
 		return nil, err
@@ -74,32 +180,101 @@ func (service *QueryService) DoQuery(q Query) (*QueryResponse, error) {
 	if err != nil {
 		return nil, err
 	}
-
-	req.Header.Set("Authorization", "Bearer "+service.Config.AccessToken)
 	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	return req, nil
+}
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
+func (service *QueryService) newGetRequest(q Query) (*http.Request, error) {
+	params, err := q.urlValues()
 	if err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
 
-	if resp.ContentLength <= 0 {
-		return nil, errors.New("Content length is 0")
+	u, err := url.Parse(service.queryURL)
+	if err != nil {
+		return nil, err
+	}
+	values := u.Query()
+	for name, v := range params {
+		values[name] = v
+	}
+	u.RawQuery = values.Encode()
+
+	service.debug("API AI request URL:", u.String())
+
+	return http.NewRequest("GET", u.String(), nil)
+}
+
+//urlValues encodes the query as GET parameters.
+func (q *Query) urlValues() (url.Values, error) {
+	if len(q.Query) > 1 || len(q.Confidence) > 0 {
+		return nil, fmt.Errorf("%w: only single query text without confidence is supported", ErrNotEncodableAsGET)
+	}
+	if len(q.Entities) > 0 {
+		return nil, fmt.Errorf("%w: entities are not supported", ErrNotEncodableAsGET)
 	}
 
-	body, _ := ioutil.ReadAll(resp.Body)
+	values := url.Values{}
+	if len(q.Query) == 1 {
+		values.Set("query", q.Query[0])
+	}
+	if q.Event != nil {
+		if len(q.Event.Data) > 0 {
+			return nil, fmt.Errorf("%w: event data is not supported", ErrNotEncodableAsGET)
+		}
+		values.Set("e", q.Event.Name)
+	}
+	for _, c := range q.Contexts {
+		if len(c.Parameters) > 0 || c.Lifespan != 0 {
+			return nil, fmt.Errorf("%w: context %q has parameters or lifespan", ErrNotEncodableAsGET, c.Name)
+		}
+		values.Add("contexts", c.Name)
+	}
+	if q.ResetContexts {
+		values.Set("resetContexts", "true")
+	}
+	if q.Timezone != "" {
+		values.Set("timezone", q.Timezone)
+	}
+	if q.Location != nil {
+		values.Set("latitude", strconv.FormatFloat(q.Location.Latitude, 'f', -1, 64))
+		values.Set("longitude", strconv.FormatFloat(q.Location.Longitude, 'f', -1, 64))
+	}
+	values.Set("lang", q.Lang)
+	values.Set("sessionId", q.SessionID)
+	return values, nil
+}
+
+//query sends prepared request and decodes QueryResponse.
+func (service *QueryService) query(ctx context.Context, req *http.Request) (*QueryResponse, error) {
+	resp, err := service.send(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := readBody(ctx, resp)
+	if err != nil {
+		return nil, err
+	}
 	service.debug("API AI response Body:", string(body))
 
+	if resp.StatusCode != http.StatusOK {
+		return nil, newAPIError(resp, body)
+	}
+
+	if len(body) == 0 {
+		return nil, errors.New("Content length is 0")
+	}
+
 	queryResponse := &QueryResponse{}
 	err = queryResponse.Decode(body)
 	if err != nil {
 		return nil, errors.New("Error parse body response:" + err.Error() + " Body:" + string(body))
 	}
 
-	if resp.StatusCode != http.StatusOK || queryResponse.Status.IsSuccess() == false {
-		return nil, errors.New("Http Status " + resp.Status + " Body:" + string(body))
+	if queryResponse.Status.IsSuccess() == false {
+		return nil, newAPIError(resp, body)
 	}
 	return queryResponse, nil
 }
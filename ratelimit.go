@@ -0,0 +1,124 @@
+package gapiai
+
+/***********************************************************************************************************************
+ *
+ * Go client-side library for API.AI
+ * =================================================
+ *
+ * Copyright (C) 2017 by Slava Vasylyev
+ *
+ *
+ * *********************************************************************************************************************
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance with
+ * the License. You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ *
+ ***********************************************************************************************************************/
+
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sync"
+	"time"
+)
+
+type (
+	//Limiter is consulted before every request sent by endpoints created from the same ApiConfig.
+	//RateLimiter implements it; so does rate.Limiter from golang.org/x/time/rate.
+	Limiter interface {
+		Wait(ctx context.Context) error
+	}
+
+	//RateLimiter is a token bucket refilled at a constant requests-per-second rate up to its burst size.
+	//It is safe for concurrent use.
+	RateLimiter struct {
+		mu     sync.Mutex
+		rate   float64
+		burst  float64
+		tokens float64
+		last   time.Time
+	}
+)
+
+//ErrRateLimitExceeded is returned by RateLimiter.Wait when the context deadline expires before a token is available.
+//The request is not sent then. It differs from ErrRateLimited, which is API.AI responding with 429,
+//and IsRateLimited is false for it.
+var ErrRateLimitExceeded = errors.New("gapiai: rate limit exceeded")
+
+//NewRateLimiter returns a full bucket of burst tokens refilled at requestsPerSecond.
+func NewRateLimiter(requestsPerSecond float64, burst int) *RateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &RateLimiter{
+		rate:   requestsPerSecond,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+//refill must be called with mu held.
+func (l *RateLimiter) refill(now time.Time) {
+	if elapsed := now.Sub(l.last); elapsed > 0 {
+		l.tokens = math.Min(l.burst, l.tokens+elapsed.Seconds()*l.rate)
+		l.last = now
+	}
+}
+
+//Allow takes a token if one is available right now.
+func (l *RateLimiter) Allow() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.refill(time.Now())
+	if l.tokens < 1 {
+		return false
+	}
+	l.tokens--
+	return true
+}
+
+//Wait blocks until a token is available or ctx is done. It fails fast with ErrRateLimitExceeded when
+//the token would not be available before the ctx deadline.
+func (l *RateLimiter) Wait(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	now := time.Now()
+	l.refill(now)
+	if l.tokens >= 1 {
+		l.tokens--
+		l.mu.Unlock()
+		return nil
+	}
+	if l.rate <= 0 {
+		l.mu.Unlock()
+		return ErrRateLimitExceeded
+	}
+	delay := time.Duration((1 - l.tokens) / l.rate * float64(time.Second))
+	if deadline, ok := ctx.Deadline(); ok && now.Add(delay).After(deadline) {
+		l.mu.Unlock()
+		return ErrRateLimitExceeded
+	}
+	//reserve the token, so concurrent waiters queue up behind this one
+	l.tokens--
+	l.mu.Unlock()
+
+	if err := sleep(ctx, delay); err != nil {
+		l.mu.Lock()
+		l.tokens = math.Min(l.burst, l.tokens+1)
+		l.mu.Unlock()
+		return err
+	}
+	return nil
+}
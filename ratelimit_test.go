@@ -0,0 +1,89 @@
+package gapiai_test
+
+/***********************************************************************************************************************
+ *
+ * Go client-side library for API.AI
+ * =================================================
+ *
+ * Copyright (C) 2017 by Slava Vasylyev
+ *
+ *
+ * *********************************************************************************************************************
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance with
+ * the License. You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ *
+ ***********************************************************************************************************************/
+
+
+import (
+	. "github.com/slavaVA/go-api.ai"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"context"
+	"github.com/onsi/gomega/ghttp"
+	"io"
+	"net/http"
+	"time"
+)
+
+var _ = Describe("RateLimiter", func() {
+
+	It("Should allow burst and then refuse", func() {
+		limiter := NewRateLimiter(0.001, 2)
+		Ω(limiter.Allow()).Should(BeTrue())
+		Ω(limiter.Allow()).Should(BeTrue())
+		Ω(limiter.Allow()).Should(BeFalse())
+	})
+
+	It("Should block until token is refilled", func() {
+		limiter := NewRateLimiter(50, 1)
+		start := time.Now()
+		Ω(limiter.Wait(context.Background())).Should(Succeed())
+		Ω(limiter.Wait(context.Background())).Should(Succeed())
+		Ω(limiter.Wait(context.Background())).Should(Succeed())
+		Ω(time.Since(start)).Should(BeNumerically(">=", 35*time.Millisecond))
+	})
+
+	It("Should fail fast when token is not available before deadline", func() {
+		limiter := NewRateLimiter(0.001, 1)
+		Ω(limiter.Wait(context.Background())).Should(Succeed())
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+		start := time.Now()
+		Ω(limiter.Wait(ctx)).Should(Equal(ErrRateLimitExceeded))
+		Ω(time.Since(start)).Should(BeNumerically("<", time.Second))
+	})
+
+	It("Should be shared by endpoints created from the same config", func() {
+		server := ghttp.NewServer()
+		defer server.Close()
+		server.AppendHandlers(
+			ghttp.RespondWith(http.StatusOK, `{"status":{"code":200},"sessionId":"1"}`),
+		)
+		apiConfig := &ApiConfig{
+			AccessToken: "123456789",
+			Lang:        English,
+			Limiter:     NewRateLimiter(0.001, 1),
+		}
+		queryService := NewQueryAPIEndpoint(server.URL()+"/v1/", CurrentAPIVersion, apiConfig)
+		ttsService := NewTtsAPIEndpoint(server.URL()+"/v1/", CurrentAPIVersion, apiConfig)
+
+		_, err := queryService.TextRequest("1", "Hello")
+		Ω(err).ShouldNot(HaveOccurred())
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		err = ttsService.DoTtsContext(ctx, "Hello", func(r io.Reader) error { return nil })
+		Ω(err).Should(Equal(ErrRateLimitExceeded))
+		Ω(server.ReceivedRequests()).Should(HaveLen(1))
+	})
+})
@@ -0,0 +1,171 @@
+package gapiai
+
+/***********************************************************************************************************************
+ *
+ * Go client-side library for API.AI
+ * =================================================
+ *
+ * Copyright (C) 2017 by Slava Vasylyev
+ *
+ *
+ * *********************************************************************************************************************
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance with
+ * the License. You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ *
+ ***********************************************************************************************************************/
+
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"math"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+type (
+	//RetryPolicy controls how DoQuery and DoTts repeat requests failed with a transient error:
+	//a transport error or a response with one of RetryableStatus codes.
+	RetryPolicy struct {
+		//MaxAttempts is the total number of attempts, including the first one. Values below 2 disable retries.
+		MaxAttempts int
+		//InitialBackoff is the delay before the second attempt.
+		InitialBackoff time.Duration
+		//MaxBackoff caps the computed delay. Zero means no cap.
+		MaxBackoff time.Duration
+		//Multiplier grows the delay after each attempt. Values below 1 are treated as 1.
+		Multiplier float64
+		//Jitter is the fraction (0..1) of the delay that is randomized to spread concurrent retries.
+		Jitter float64
+		//RetryableStatus lists HTTP status codes worth retrying. DefaultRetryableStatus is used when nil.
+		RetryableStatus []int
+		//RespectRetryAfter makes the Retry-After response header take precedence over the computed delay.
+		RespectRetryAfter bool
+		//OnAttempt, when set, is called after every attempt.
+		OnAttempt func(RetryAttempt)
+	}
+
+	//RetryAttempt describes the outcome of a single attempt.
+	RetryAttempt struct {
+		//Attempt is the attempt number starting from 1.
+		Attempt  int
+		Request  *http.Request
+		Response *http.Response
+		Err      error
+		//Retry reports whether another attempt follows after Delay.
+		Retry bool
+		Delay time.Duration
+	}
+)
+
+var DefaultRetryableStatus = []int{
+	http.StatusTooManyRequests,
+	http.StatusInternalServerError,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+//DefaultRetryPolicy returns a policy making up to 3 attempts with exponential backoff from 200ms to 5s.
+func DefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts:       3,
+		InitialBackoff:    200 * time.Millisecond,
+		MaxBackoff:        5 * time.Second,
+		Multiplier:        2,
+		Jitter:            0.5,
+		RespectRetryAfter: true,
+	}
+}
+
+//Backoff returns the delay before the attempt following the given one, jitter included.
+func (policy *RetryPolicy) Backoff(attempt int) time.Duration {
+	multiplier := math.Max(policy.Multiplier, 1)
+	delay := float64(policy.InitialBackoff) * math.Pow(multiplier, float64(attempt-1))
+	if policy.MaxBackoff > 0 && delay > float64(policy.MaxBackoff) {
+		delay = float64(policy.MaxBackoff)
+	}
+	if jitter := math.Min(math.Max(policy.Jitter, 0), 1); jitter > 0 {
+		delay -= delay * jitter * rand.Float64()
+	}
+	return time.Duration(delay)
+}
+
+func (policy *RetryPolicy) isRetryableStatus(code int) bool {
+	codes := policy.RetryableStatus
+	if codes == nil {
+		codes = DefaultRetryableStatus
+	}
+	for _, c := range codes {
+		if c == code {
+			return true
+		}
+	}
+	return false
+}
+
+//next decides whether the attempt should be repeated and after which delay.
+func (policy *RetryPolicy) next(attempt int, resp *http.Response, err error) (time.Duration, bool) {
+	if policy == nil || attempt >= policy.MaxAttempts {
+		return 0, false
+	}
+	if err != nil {
+		return policy.Backoff(attempt), true
+	}
+	if !policy.isRetryableStatus(resp.StatusCode) {
+		return 0, false
+	}
+	if policy.RespectRetryAfter {
+		if delay, ok := retryAfter(resp); ok {
+			return delay, true
+		}
+	}
+	return policy.Backoff(attempt), true
+}
+
+//retryAfter parses Retry-After header given either in seconds or as HTTP date.
+func retryAfter(resp *http.Response) (time.Duration, bool) {
+	value := resp.Header.Get("Retry-After")
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		delay := time.Until(date)
+		if delay < 0 {
+			delay = 0
+		}
+		return delay, true
+	}
+	return 0, false
+}
+
+//sleep waits for delay unless ctx is done first.
+func sleep(ctx context.Context, delay time.Duration) error {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+//discard releases the connection of a response that is not going to be returned.
+func discard(resp *http.Response) {
+	io.Copy(ioutil.Discard, io.LimitReader(resp.Body, 4096))
+	resp.Body.Close()
+}
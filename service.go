@@ -22,35 +22,249 @@ package gapiai
  ***********************************************************************************************************************/
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
 	"io"
+	"io/ioutil"
 	"log"
 	"math/rand"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
 )
 
 type (
 	ApiConfig struct {
 		AccessToken string
-		Lang        SupportedLang
+		//DeveloperAccessToken is used by agent management endpoints, AccessToken is used when it is empty.
+		DeveloperAccessToken string
+		//Lang, Timezone and Location are sent with queries that do not set their own values.
+		Lang     SupportedLang
+		Timezone string
+		Location *Location
+		//HTTPClient is used by all endpoints created with this config. It takes precedence over Transport.
+		HTTPClient *http.Client
+		//Transport is wrapped into a client with DefaultTimeout when HTTPClient is not set.
+		Transport http.RoundTripper
+		//Limiter, when set, is waited on before every request, retries included, so all endpoints
+		//sharing the config share its budget.
+		Limiter Limiter
 	}
 
 	ApiService struct {
 		logger *log.Logger
 		Config *ApiConfig
+		//Retry is applied to every request of the service. Requests are not retried when it is nil.
+		Retry *RetryPolicy
+		//developer selects Config.DeveloperAccessToken for requests of the service.
+		developer bool
 	}
 )
 
+//DefaultTimeout limits the whole request, including reading of the response body.
+const DefaultTimeout = 30 * time.Second
+
 var letterRunes = []rune("abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ1234567890")
 
+//DefaultTransport is shared by all endpoints whose config sets neither HTTPClient nor Transport.
+//It may be replaced, e.g. in tests, and is used by requests sent after that.
+var DefaultTransport http.RoundTripper = &http.Transport{
+	Proxy: http.ProxyFromEnvironment,
+	DialContext: (&net.Dialer{
+		Timeout:   10 * time.Second,
+		KeepAlive: 30 * time.Second,
+	}).DialContext,
+	MaxIdleConns:          100,
+	MaxIdleConnsPerHost:   10,
+	IdleConnTimeout:       90 * time.Second,
+	TLSHandshakeTimeout:   10 * time.Second,
+	ExpectContinueTimeout: 1 * time.Second,
+}
+
+//NewHTTPClient returns a client using transport with DefaultTimeout.
+func NewHTTPClient(transport http.RoundTripper) *http.Client {
+	return &http.Client{
+		Transport: transport,
+		Timeout:   DefaultTimeout,
+	}
+}
+
+func (cfg *ApiConfig) httpClient() *http.Client {
+	if cfg.HTTPClient != nil {
+		return cfg.HTTPClient
+	}
+	if cfg.Transport != nil {
+		return NewHTTPClient(cfg.Transport)
+	}
+	//DefaultTransport is resolved on every call, so reassigning it takes effect
+	return NewHTTPClient(DefaultTransport)
+}
+
 func (service *ApiService) EnableLogger(w io.Writer) {
 	service.logger = log.New(w,
 		"DEBUG: ",
 		log.Ldate|log.Ltime|log.Lshortfile)
 }
 
+func (service *ApiService) accessToken() string {
+	if service.developer && service.Config.DeveloperAccessToken != "" {
+		return service.Config.DeveloperAccessToken
+	}
+	return service.Config.AccessToken
+}
+
 func (service *ApiService) debug(v ...interface{}) {
 	if service.logger != nil {
-		service.logger.Println(v)
+		service.logger.Println(v...)
+	}
+}
+
+//send performs the request bound to ctx, repeating it according to the Retry policy. The request body
+//is replayed with req.GetBody, so requests with a body but without GetBody are sent once. If the request fails because ctx
+//was cancelled or its deadline exceeded, the context error is returned as is, so callers can compare it
+//with context.Canceled and context.DeadlineExceeded.
+func (service *ApiService) send(ctx context.Context, req *http.Request) (*http.Response, error) {
+	for attempt := 1; ; attempt++ {
+		r := req.WithContext(ctx)
+		if attempt > 1 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			r.Body = body
+		}
+
+		if service.Config.Limiter != nil {
+			if err := service.Config.Limiter.Wait(ctx); err != nil {
+				return nil, err
+			}
+		}
+
+		resp, err := service.Config.httpClient().Do(r)
+		if err != nil {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return nil, ctxErr
+			}
+		}
+
+		delay, retry := service.Retry.next(attempt, resp, err)
+		if req.Body != nil && req.GetBody == nil {
+			retry = false
+		}
+		if deadline, ok := ctx.Deadline(); ok && time.Now().Add(delay).After(deadline) {
+			retry = false
+		}
+		if !retry {
+			delay = 0
+		}
+		if service.Retry != nil && service.Retry.OnAttempt != nil {
+			service.Retry.OnAttempt(RetryAttempt{
+				Attempt:  attempt,
+				Request:  r,
+				Response: resp,
+				Err:      err,
+				Retry:    retry,
+				Delay:    delay,
+			})
+		}
+		if !retry {
+			return resp, err
+		}
+
+		service.debug("Retry attempt", attempt+1, "after", delay)
+		if resp != nil {
+			discard(resp)
+		}
+		if err := sleep(ctx, delay); err != nil {
+			return nil, err
+		}
+	}
+}
+
+//readBody reads the response body. If reading fails because ctx was cancelled or its deadline exceeded,
+//the context error is returned instead of the read error.
+func readBody(ctx context.Context, resp *http.Response) ([]byte, error) {
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
+		return nil, err
+	}
+	return body, nil
+}
+
+//doJSON sends in encoded as JSON, when it is not nil, and decodes the successful response into out,
+//when it is not nil. Unsuccessful HTTP status or status object in the response is returned as APIError.
+func (service *ApiService) doJSON(ctx context.Context, method string, url string, in interface{}, out interface{}) error {
+	var body io.Reader
+	if in != nil {
+		jsonStr, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		service.debug("API AI request Body:", string(jsonStr))
+		body = bytes.NewReader(jsonStr)
+	}
+
+	req, err := http.NewRequest(method, url, body)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Authorization", "Bearer "+service.accessToken())
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	}
+
+	resp, err := service.send(ctx, req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := readBody(ctx, resp)
+	if err != nil {
+		return err
+	}
+	service.debug("API AI response Body:", string(respBody))
+
+	if resp.StatusCode != http.StatusOK {
+		return newAPIError(resp, respBody)
+	}
+
+	//responses which are not arrays carry status object
+	var status struct {
+		Status *StatusObject `json:"status"`
+	}
+	if json.Unmarshal(respBody, &status) == nil && status.Status != nil && !status.Status.IsSuccess() {
+		return newAPIError(resp, respBody)
+	}
+
+	if out != nil {
+		if err := json.Unmarshal(respBody, out); err != nil {
+			return errors.New("Error parse body response:" + err.Error() + " Body:" + string(respBody))
+		}
+	}
+	return nil
+}
+
+//endpointURL joins escaped path elements to base and appends version and params as the URL query.
+func endpointURL(base string, version string, params url.Values, path ...string) string {
+	u := base
+	for _, p := range path {
+		u = strings.TrimSuffix(u, "/") + "/" + url.PathEscape(p)
+	}
+	values := url.Values{}
+	for name, v := range params {
+		values[name] = v
 	}
+	values.Set("v", version)
+	return u + "?" + values.Encode()
 }
 
 func NewSessionId() string {
@@ -26,12 +26,24 @@ import (
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+	"context"
+	"errors"
 	"github.com/onsi/gomega/ghttp"
 	"io"
 	"net/http"
 	"os"
+	"time"
 )
 
+type countingTransport struct {
+	requests int
+}
+
+func (t *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.requests++
+	return http.DefaultTransport.RoundTrip(req)
+}
+
 var _ = Describe("Service", func() {
 	var server *ghttp.Server
 
@@ -110,6 +122,159 @@ var _ = Describe("Service", func() {
 			Ω(response.SessionID).Should(Equal(sessionId))
 			Ω(response.Status.Code).Should(Equal(200))
 		})
+
+		It("Should return context error when request is cancelled", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			response, err := apiService.TextRequestContext(ctx, "111", "RequestText")
+			Ω(err).Should(Equal(context.Canceled))
+			Ω(response).Should(BeNil())
+			Ω(server.ReceivedRequests()).Should(HaveLen(0))
+		})
+	})
+
+	Describe("Response body", func() {
+		It("Should return context error when deadline exceeds while reading body", func() {
+			server.AppendHandlers(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+				w.Write([]byte(`{"status": {"code": 200}, `))
+				w.(http.Flusher).Flush()
+				select {
+				case <-r.Context().Done():
+				case <-time.After(time.Second):
+				}
+			})
+			apiConfig := &ApiConfig{
+				AccessToken: "123456789",
+				Lang:        English,
+			}
+			apiService := NewQueryAPIEndpoint(server.URL()+"/v1/", CurrentAPIVersion, apiConfig)
+			ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+			defer cancel()
+			response, err := apiService.TextRequestContext(ctx, "111", "RequestText")
+			Ω(err).Should(Equal(context.DeadlineExceeded))
+			Ω(response).Should(BeNil())
+		})
+	})
+
+	Describe("GET Query", func() {
+		var apiService *QueryService
+		BeforeEach(func() {
+			apiConfig := &ApiConfig{
+				AccessToken: "123456789",
+				Lang:        English,
+			}
+			apiService = NewQueryAPIEndpoint(server.URL()+"/v1/", CurrentAPIVersion, apiConfig)
+			apiService.UseGET = true
+		})
+
+		It("Should encode query fields as URL parameters", func() {
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/v1/query",
+						"contexts=weather&contexts=music&lang=en&latitude=37.4256293&longitude=-122.20539"+
+							"&query=Hello+world%26more&resetContexts=true&sessionId=111&timezone=Europe%2FKiev&v=20150910"),
+					ghttp.VerifyHeader(http.Header{
+						"Authorization": []string{"Bearer 123456789"},
+					}),
+					ghttp.RespondWith(http.StatusOK, `{"status":{"code":200},"sessionId":"111"}`),
+				),
+			)
+			q := Query{
+				Query:         []string{"Hello world&more"},
+				SessionID:     "111",
+				Contexts:      []DialogContext{{Name: "weather"}, {Name: "music"}},
+				ResetContexts: true,
+				Timezone:      "Europe/Kiev",
+				Location:      &Location{Latitude: 37.4256293, Longitude: -122.20539},
+			}
+			response, err := apiService.DoQuery(q)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(response.SessionID).Should(Equal("111"))
+		})
+
+		It("Should reject fields not representable as URL parameters", func() {
+			q := Query{
+				Query:     []string{"Hello"},
+				SessionID: "111",
+				Contexts: []DialogContext{{
+					Name:       "weather",
+					Parameters: map[string]interface{}{"city": "Kiev"},
+				}},
+			}
+			_, err := apiService.DoQuery(q)
+			Ω(errors.Is(err, ErrNotEncodableAsGET)).Should(BeTrue())
+			Ω(server.ReceivedRequests()).Should(HaveLen(0))
+		})
+	})
+
+	Describe("Query overrides", func() {
+		var apiService *QueryService
+		BeforeEach(func() {
+			apiConfig := &ApiConfig{
+				AccessToken: "123456789",
+				Lang:        English,
+				Timezone:    "Europe/Kiev",
+				Location:    &Location{Latitude: 50.45, Longitude: 30.52},
+			}
+			apiService = NewQueryAPIEndpoint(server.URL()+"/v1/", CurrentAPIVersion, apiConfig)
+		})
+
+		It("Should fill language, timezone and location from config", func() {
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyJSON(`{
+						"query": ["Hello"],
+						"timezone": "Europe/Kiev",
+						"lang": "en",
+						"sessionId": "111",
+						"location": {"latitude": 50.45, "longitude": 30.52}
+					}`),
+					ghttp.RespondWith(http.StatusOK, `{"status":{"code":200},"sessionId":"111"}`),
+				),
+			)
+			_, err := apiService.TextRequest("111", "Hello")
+			Ω(err).ShouldNot(HaveOccurred())
+		})
+
+		It("Should prefer values of the query", func() {
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyJSON(`{
+						"query": ["Hallo"],
+						"timezone": "Europe/Berlin",
+						"lang": "de",
+						"sessionId": "111",
+						"location": {"latitude": 52.52, "longitude": 13.4}
+					}`),
+					ghttp.RespondWith(http.StatusOK, `{"status":{"code":200},"sessionId":"111"}`),
+				),
+			)
+			_, err := apiService.DoQuery(Query{
+				Query:     []string{"Hallo"},
+				SessionID: "111",
+				Lang:      string(German),
+				Timezone:  "Europe/Berlin",
+				Location:  &Location{Latitude: 52.52, Longitude: 13.4},
+			})
+			Ω(err).ShouldNot(HaveOccurred())
+		})
+
+		It("Should reject invalid language, timezone and location", func() {
+			q := Query{Query: []string{"Hello"}, SessionID: "111", Lang: "xx"}
+			_, err := apiService.DoQuery(q)
+			Ω(errors.Is(err, ErrUnsupportedLanguage)).Should(BeTrue())
+			Ω(errors.Is(err, ErrInvalidQuery)).Should(BeTrue())
+
+			q = Query{Query: []string{"Hello"}, SessionID: "111", Timezone: "Mars/Olympus"}
+			_, err = apiService.DoQuery(q)
+			Ω(errors.Is(err, ErrInvalidQuery)).Should(BeTrue())
+
+			q = Query{Query: []string{"Hello"}, SessionID: "111", Location: &Location{Latitude: 91}}
+			_, err = apiService.DoQuery(q)
+			Ω(errors.Is(err, ErrInvalidQuery)).Should(BeTrue())
+			Ω(server.ReceivedRequests()).Should(HaveLen(0))
+		})
 	})
 
 	Describe("TTS", func() {
@@ -152,5 +317,246 @@ var _ = Describe("Service", func() {
 			Ω(err).ShouldNot(HaveOccurred())
 			Ω(server.ReceivedRequests()).Should(HaveLen(1))
 		})
+
+		It("Should return context error when deadline is exceeded", func() {
+			ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+			defer cancel()
+			err := apiService.DoTtsContext(ctx, "Hello", func(r io.Reader) error {
+				Fail("handler must not be called")
+				return nil
+			})
+			Ω(err).Should(Equal(context.DeadlineExceeded))
+			Ω(server.ReceivedRequests()).Should(HaveLen(0))
+		})
+	})
+
+	Describe("HTTP transport", func() {
+		It("Should share configured transport between endpoints", func() {
+			server.AppendHandlers(
+				ghttp.RespondWith(http.StatusOK, `{"status":{"code":200},"sessionId":"1"}`),
+				ghttp.RespondWith(http.StatusOK, []byte{1, 2, 3}),
+			)
+			transport := &countingTransport{}
+			apiConfig := &ApiConfig{
+				AccessToken: "123456789",
+				Lang:        English,
+				Transport:   transport,
+			}
+			queryService := NewQueryAPIEndpoint(server.URL()+"/v1/", CurrentAPIVersion, apiConfig)
+			ttsService := NewTtsAPIEndpoint(server.URL()+"/v1/", CurrentAPIVersion, apiConfig)
+
+			_, err := queryService.TextRequest("1", "Hello")
+			Ω(err).ShouldNot(HaveOccurred())
+			err = ttsService.DoTts("Hello", func(r io.Reader) error { return nil })
+			Ω(err).ShouldNot(HaveOccurred())
+
+			Ω(transport.requests).Should(Equal(2))
+			Ω(server.ReceivedRequests()).Should(HaveLen(2))
+		})
+
+		It("Should use replaced DefaultTransport", func() {
+			server.AppendHandlers(
+				ghttp.RespondWith(http.StatusOK, `{"status":{"code":200},"sessionId":"1"}`),
+			)
+			transport := &countingTransport{}
+			saved := DefaultTransport
+			DefaultTransport = transport
+			defer func() { DefaultTransport = saved }()
+
+			apiConfig := &ApiConfig{
+				AccessToken: "123456789",
+				Lang:        English,
+			}
+			queryService := NewQueryAPIEndpoint(server.URL()+"/v1/", CurrentAPIVersion, apiConfig)
+			_, err := queryService.TextRequest("1", "Hello")
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(transport.requests).Should(Equal(1))
+		})
+	})
+
+	Describe("API errors", func() {
+		var apiConfig *ApiConfig
+		BeforeEach(func() {
+			apiConfig = &ApiConfig{
+				AccessToken: "bad",
+				Lang:        English,
+			}
+		})
+
+		It("Should return APIError with decoded status object", func() {
+			server.AppendHandlers(
+				ghttp.RespondWith(http.StatusUnauthorized, `{
+					"status": {
+						"code": 401,
+						"errorType": "unauthorized",
+						"errorId": "5e9e31c3-f1d1-4ab4-a7a6-a8b4c0ab3e7f",
+						"errorDetails": "Authentication parameters missing"
+					}
+				}`),
+			)
+			apiService := NewQueryAPIEndpoint(server.URL()+"/v1/", CurrentAPIVersion, apiConfig)
+			response, err := apiService.TextRequest("1", "Hello")
+			Ω(response).Should(BeNil())
+
+			var apiErr *APIError
+			Ω(errors.As(err, &apiErr)).Should(BeTrue())
+			Ω(apiErr.HTTPStatus).Should(Equal(http.StatusUnauthorized))
+			Ω(apiErr.Code).Should(Equal(401))
+			Ω(apiErr.ErrorType).Should(Equal("unauthorized"))
+			Ω(apiErr.ErrorID).Should(Equal("5e9e31c3-f1d1-4ab4-a7a6-a8b4c0ab3e7f"))
+			Ω(apiErr.ErrorDetails).Should(Equal("Authentication parameters missing"))
+			Ω(IsUnauthorized(err)).Should(BeTrue())
+			Ω(IsRateLimited(err)).Should(BeFalse())
+		})
+
+		It("Should return APIError when status object reports failure", func() {
+			server.AppendHandlers(
+				ghttp.RespondWith(http.StatusOK, `{"status":{"code":404,"errorType":"not_found"},"sessionId":"1"}`),
+			)
+			apiService := NewQueryAPIEndpoint(server.URL()+"/v1/", CurrentAPIVersion, apiConfig)
+			_, err := apiService.TextRequest("1", "Hello")
+			Ω(IsNotFound(err)).Should(BeTrue())
+			Ω(errors.Is(err, ErrNotFound)).Should(BeTrue())
+		})
+
+		It("Should return APIError from TTS without JSON body", func() {
+			server.AppendHandlers(
+				ghttp.RespondWith(http.StatusTooManyRequests, "Too many requests"),
+			)
+			apiService := NewTtsAPIEndpoint(server.URL()+"/v1/", CurrentAPIVersion, apiConfig)
+			err := apiService.DoTts("Hello", func(r io.Reader) error { return nil })
+
+			var apiErr *APIError
+			Ω(errors.As(err, &apiErr)).Should(BeTrue())
+			Ω(apiErr.Code).Should(Equal(http.StatusTooManyRequests))
+			Ω(apiErr.ErrorDetails).Should(Equal("Too many requests"))
+			Ω(IsRateLimited(err)).Should(BeTrue())
+		})
+	})
+
+	Describe("Retry", func() {
+		requestJSON := `{"query":["Hello"],"lang":"en","sessionId":"1"}`
+		successJSON := `{"status":{"code":200},"sessionId":"1"}`
+		var apiService *QueryService
+		var attempts []RetryAttempt
+		BeforeEach(func() {
+			attempts = nil
+			apiConfig := &ApiConfig{
+				AccessToken: "123456789",
+				Lang:        English,
+			}
+			apiService = NewQueryAPIEndpoint(server.URL()+"/v1/", CurrentAPIVersion, apiConfig)
+			apiService.Retry = &RetryPolicy{
+				MaxAttempts:       3,
+				InitialBackoff:    time.Millisecond,
+				Multiplier:        2,
+				Jitter:            0.5,
+				RespectRetryAfter: true,
+				OnAttempt: func(a RetryAttempt) {
+					attempts = append(attempts, a)
+				},
+			}
+		})
+
+		It("Should replay request body after transient failure", func() {
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyJSON(requestJSON),
+					ghttp.RespondWith(http.StatusServiceUnavailable, "Service Unavailable"),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyJSON(requestJSON),
+					ghttp.RespondWith(http.StatusTooManyRequests, "", http.Header{"Retry-After": []string{"0"}}),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyJSON(requestJSON),
+					ghttp.RespondWith(http.StatusOK, successJSON),
+				),
+			)
+			response, err := apiService.TextRequest("1", "Hello")
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(response.SessionID).Should(Equal("1"))
+			Ω(server.ReceivedRequests()).Should(HaveLen(3))
+
+			Ω(attempts).Should(HaveLen(3))
+			Ω(attempts[0].Retry).Should(BeTrue())
+			Ω(attempts[0].Response.StatusCode).Should(Equal(http.StatusServiceUnavailable))
+			Ω(attempts[1].Retry).Should(BeTrue())
+			Ω(attempts[1].Delay).Should(BeZero())
+			Ω(attempts[2].Attempt).Should(Equal(3))
+			Ω(attempts[2].Retry).Should(BeFalse())
+		})
+
+		It("Should retry TTS request without body", func() {
+			server.AppendHandlers(
+				ghttp.RespondWith(http.StatusServiceUnavailable, "Service Unavailable"),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/v1/tts", "v=20150910&text=Hello"),
+					ghttp.RespondWith(http.StatusOK, []byte{1, 2, 3}),
+				),
+			)
+			ttsService := NewTtsAPIEndpoint(server.URL()+"/v1/", CurrentAPIVersion, apiService.Config)
+			ttsService.Retry = apiService.Retry
+			err := ttsService.DoTts("Hello", func(r io.Reader) error { return nil })
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(server.ReceivedRequests()).Should(HaveLen(2))
+			Ω(attempts).Should(HaveLen(2))
+		})
+
+		It("Should retry GET request of management endpoints", func() {
+			server.AppendHandlers(
+				ghttp.RespondWith(http.StatusBadGateway, "Bad Gateway"),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/v1/contexts", "sessionId=1&v=20150910"),
+					ghttp.RespondWith(http.StatusOK, `[{"name": "weather", "parameters": {}, "lifespan": 2}]`),
+				),
+			)
+			contextsService := NewContextsAPIEndpoint(server.URL()+"/v1/", CurrentAPIVersion, apiService.Config)
+			contextsService.Retry = apiService.Retry
+			contexts, err := contextsService.List("1")
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(contexts).Should(HaveLen(1))
+			Ω(server.ReceivedRequests()).Should(HaveLen(2))
+		})
+
+		It("Should give up after MaxAttempts", func() {
+			server.AppendHandlers(
+				ghttp.RespondWith(http.StatusBadGateway, "Bad Gateway"),
+				ghttp.RespondWith(http.StatusBadGateway, "Bad Gateway"),
+				ghttp.RespondWith(http.StatusBadGateway, "Bad Gateway"),
+			)
+			_, err := apiService.TextRequest("1", "Hello")
+			var apiErr *APIError
+			Ω(errors.As(err, &apiErr)).Should(BeTrue())
+			Ω(apiErr.HTTPStatus).Should(Equal(http.StatusBadGateway))
+			Ω(server.ReceivedRequests()).Should(HaveLen(3))
+		})
+
+		It("Should not retry non-retryable status", func() {
+			server.AppendHandlers(
+				ghttp.RespondWith(http.StatusBadRequest, `{"status":{"code":400,"errorType":"bad_request"}}`),
+			)
+			_, err := apiService.TextRequest("1", "Hello")
+			Ω(err).Should(HaveOccurred())
+			Ω(server.ReceivedRequests()).Should(HaveLen(1))
+			Ω(attempts).Should(HaveLen(1))
+			Ω(attempts[0].Retry).Should(BeFalse())
+		})
+
+		It("Should compute capped exponential backoff", func() {
+			policy := &RetryPolicy{
+				InitialBackoff: 100 * time.Millisecond,
+				MaxBackoff:     300 * time.Millisecond,
+				Multiplier:     2,
+			}
+			Ω(policy.Backoff(1)).Should(Equal(100 * time.Millisecond))
+			Ω(policy.Backoff(2)).Should(Equal(200 * time.Millisecond))
+			Ω(policy.Backoff(3)).Should(Equal(300 * time.Millisecond))
+
+			policy.Jitter = 0.5
+			for i := 0; i < 10; i++ {
+				Ω(policy.Backoff(2)).Should(BeNumerically("~", 150*time.Millisecond, 50*time.Millisecond))
+			}
+		})
 	})
 })
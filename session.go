@@ -0,0 +1,260 @@
+package gapiai
+
+/***********************************************************************************************************************
+ *
+ * Go client-side library for API.AI
+ * =================================================
+ *
+ * Copyright (C) 2017 by Slava Vasylyev
+ *
+ *
+ * *********************************************************************************************************************
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance with
+ * the License. You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ *
+ ***********************************************************************************************************************/
+
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+)
+
+type (
+	//QueryDoer sends queries of a session. It is implemented by QueryService and is easy to stub in tests.
+	QueryDoer interface {
+		DoQueryContext(ctx context.Context, q Query) (*QueryResponse, error)
+	}
+
+	//Session carries the session ID across turns of a conversation and tracks its contexts client-side.
+	//Turns of a session are serialized. Session is safe for concurrent use.
+	Session struct {
+		service QueryDoer
+		//store, when set, receives the state after every successful turn
+		store SessionStore
+
+		//turn serializes turns, it is held for the whole request
+		turn sync.Mutex
+		//saving keeps states written to the store in the order they were taken
+		saving sync.Mutex
+		//mu guards state and is never held during I/O
+		mu    sync.Mutex
+		state SessionState
+	}
+)
+
+//NewSession starts a session with a new ID.
+func NewSession(service QueryDoer) *Session {
+	return NewSessionWithID(service, NewSessionId())
+}
+
+//NewSessionWithID continues the session with the given ID.
+func NewSessionWithID(service QueryDoer, sessionID string) *Session {
+	return &Session{
+		service: service,
+		state: SessionState{
+			ID: sessionID,
+		},
+	}
+}
+
+//LoadSession continues the session with state kept in store, or starts it if the store has no state
+//for the session. The state is saved to the store after every successful turn.
+func LoadSession(ctx context.Context, service QueryDoer, store SessionStore, sessionID string) (*Session, error) {
+	session := NewSessionWithID(service, sessionID)
+	session.store = store
+	state, err := store.Load(ctx, sessionID)
+	if errors.Is(err, ErrSessionNotFound) {
+		return session, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	session.state = *state
+	session.state.ID = sessionID
+	return session, nil
+}
+
+func (s *Session) ID() string {
+	return s.state.ID
+}
+
+//Contexts returns the contexts active after the last turn, with lifespans as returned by API.AI.
+func (s *Session) Contexts() []DialogContext {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]DialogContext(nil), s.state.Contexts...)
+}
+
+//LastAction returns the action of the last turn result.
+func (s *Session) LastAction() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.state.LastAction
+}
+
+//Get returns custom user data kept with the session.
+func (s *Session) Get(key string) (interface{}, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	value, ok := s.state.Data[key]
+	return value, ok
+}
+
+//Set stores custom user data with the session. It is saved with the next turn or Save.
+func (s *Session) Set(key string, value interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.state.Data == nil {
+		s.state.Data = map[string]interface{}{}
+	}
+	s.state.Data[key] = value
+}
+
+//State returns a copy of the session state.
+func (s *Session) State() SessionState {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.copyState()
+}
+
+//copyState must be called with mu held.
+func (s *Session) copyState() SessionState {
+	state := s.state
+	state.Contexts = append([]DialogContext(nil), s.state.Contexts...)
+	state.Pending = append([]DialogContext(nil), s.state.Pending...)
+	if s.state.Data != nil {
+		state.Data = make(map[string]interface{}, len(s.state.Data))
+		for k, v := range s.state.Data {
+			state.Data[k] = v
+		}
+	}
+	return state
+}
+
+//Save stores the session state. It does nothing for sessions without a store.
+func (s *Session) Save(ctx context.Context) error {
+	if s.store == nil {
+		return nil
+	}
+	s.saving.Lock()
+	defer s.saving.Unlock()
+
+	s.mu.Lock()
+	s.state.UpdatedAt = time.Now()
+	state := s.copyState()
+	s.mu.Unlock()
+
+	return s.store.Save(ctx, &state)
+}
+
+//SetContext adds the context to the next query, replacing a context with the same name.
+func (s *Session) SetContext(c DialogContext) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.state.Pending = mergeContexts(s.state.Pending, c)
+}
+
+func (s *Session) Send(text string) (*QueryResponse, error) {
+	return s.SendContext(context.Background(), text)
+}
+
+func (s *Session) SendContext(ctx context.Context, text string) (*QueryResponse, error) {
+	return s.DoQueryContext(ctx, Query{
+		Query: []string{text},
+	})
+}
+
+func (s *Session) SendEvent(name string, data interface{}) (*QueryResponse, error) {
+	return s.SendEventContext(context.Background(), name, data)
+}
+
+//SendEventContext triggers the event in the session. Data is converted with NewEvent.
+func (s *Session) SendEventContext(ctx context.Context, name string, data interface{}) (*QueryResponse, error) {
+	event, err := NewEvent(name, data)
+	if err != nil {
+		return nil, err
+	}
+	return s.DoQueryContext(ctx, Query{
+		Event: event,
+	})
+}
+
+//DoQueryContext sends q in the session. Contexts set with SetContext are merged into q.Contexts,
+//those of q take precedence. The contexts are kept for the next turn if the query fails.
+//If saving the state to the store fails, the response is returned along with the error.
+//Other methods of the session do not wait for the request to complete.
+func (s *Session) DoQueryContext(ctx context.Context, q Query) (*QueryResponse, error) {
+	s.turn.Lock()
+	defer s.turn.Unlock()
+
+	s.mu.Lock()
+	pending := s.state.Pending
+	s.state.Pending = nil
+	q.SessionID = s.state.ID
+	q.Contexts = mergeContexts(append([]DialogContext(nil), pending...), q.Contexts...)
+	s.mu.Unlock()
+
+	response, err := s.service.DoQueryContext(ctx, q)
+
+	s.mu.Lock()
+	if err != nil {
+		//contexts set during the request take precedence
+		s.state.Pending = mergeContexts(pending, s.state.Pending...)
+		s.mu.Unlock()
+		return nil, err
+	}
+	s.state.LastAction = response.Result.Action
+	s.advance(q, response.Result.Contexts)
+	s.mu.Unlock()
+
+	return response, s.Save(ctx)
+}
+
+//advance moves tracked contexts to the next turn: lifespans of the known and the sent ones are
+//decremented, and the ones returned by API.AI replace them all. It must be called with mu held.
+func (s *Session) advance(q Query, returned []DialogContext) {
+	var contexts []DialogContext
+	if !q.ResetContexts {
+		for _, c := range s.state.Contexts {
+			if c.Lifespan--; c.Lifespan > 0 {
+				contexts = append(contexts, c)
+			}
+		}
+	}
+	for _, c := range q.Contexts {
+		if c.Lifespan--; c.Lifespan > 0 {
+			contexts = mergeContexts(contexts, c)
+		}
+	}
+	s.state.Contexts = mergeContexts(contexts, returned...)
+}
+
+//mergeContexts adds contexts to list, replacing the ones with the same name. API.AI context names
+//are case-insensitive.
+func mergeContexts(list []DialogContext, contexts ...DialogContext) []DialogContext {
+	for _, c := range contexts {
+		replaced := false
+		for i := range list {
+			if strings.EqualFold(list[i].Name, c.Name) {
+				list[i] = c
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			list = append(list, c)
+		}
+	}
+	return list
+}
@@ -0,0 +1,173 @@
+package gapiai_test
+
+/***********************************************************************************************************************
+ *
+ * Go client-side library for API.AI
+ * =================================================
+ *
+ * Copyright (C) 2017 by Slava Vasylyev
+ *
+ *
+ * *********************************************************************************************************************
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance with
+ * the License. You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ *
+ ***********************************************************************************************************************/
+
+
+import (
+	. "github.com/slavaVA/go-api.ai"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"context"
+	"github.com/onsi/gomega/ghttp"
+	"net/http"
+)
+
+//stubDoer answers every query with the action and records the queries.
+type stubDoer struct {
+	action  string
+	queries []Query
+}
+
+func (d *stubDoer) DoQueryContext(ctx context.Context, q Query) (*QueryResponse, error) {
+	d.queries = append(d.queries, q)
+	return &QueryResponse{SessionID: q.SessionID, Result: QueryResult{Action: d.action}}, nil
+}
+
+var _ = Describe("Session", func() {
+	var server *ghttp.Server
+	var session *Session
+
+	BeforeEach(func() {
+		server = ghttp.NewServer()
+		apiConfig := &ApiConfig{
+			AccessToken: "123456789",
+			Lang:        English,
+		}
+		apiService := NewQueryAPIEndpoint(server.URL()+"/v1/", CurrentAPIVersion, apiConfig)
+		session = NewSessionWithID(apiService, "111")
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("Should generate session ID", func() {
+		Ω(NewSession(nil).ID()).Should(HaveLen(36))
+	})
+
+	It("Should work with any QueryDoer", func() {
+		doer := &stubDoer{action: "greeting"}
+		session := NewSessionWithID(doer, "222")
+		_, err := session.Send("Hi")
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(session.LastAction()).Should(Equal("greeting"))
+		Ω(doer.queries).Should(HaveLen(1))
+		Ω(doer.queries[0].SessionID).Should(Equal("222"))
+	})
+
+	It("Should send caller contexts and track returned ones across turns", func() {
+		server.AppendHandlers(
+			ghttp.CombineHandlers(
+				ghttp.VerifyJSON(`{
+					"query": ["Hi"],
+					"contexts": [{"name": "weather", "parameters": {"city": "Kiev"}, "lifespan": 2}],
+					"lang": "en",
+					"sessionId": "111"
+				}`),
+				ghttp.RespondWith(http.StatusOK, `{
+					"result": {"contexts": [
+						{"name": "weather", "parameters": {"city": "Kiev"}, "lifespan": 2},
+						{"name": "greeting", "parameters": {}, "lifespan": 1}
+					]},
+					"status": {"code": 200},
+					"sessionId": "111"
+				}`),
+			),
+			ghttp.CombineHandlers(
+				ghttp.VerifyJSON(`{
+					"event": {"name": "WELCOME", "data": {"name": "Sam"}},
+					"lang": "en",
+					"sessionId": "111"
+				}`),
+				ghttp.RespondWith(http.StatusOK, `{"result": {"contexts": []}, "status": {"code": 200}, "sessionId": "111"}`),
+			),
+		)
+
+		session.SetContext(DialogContext{Name: "weather", Lifespan: 5})
+		session.SetContext(DialogContext{Name: "weather", Lifespan: 2, Parameters: Parameters{"city": "Kiev"}})
+		_, err := session.Send("Hi")
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(session.Contexts()).Should(HaveLen(2))
+
+		_, err = session.SendEvent("WELCOME", map[string]string{"name": "Sam"})
+		Ω(err).ShouldNot(HaveOccurred())
+		contexts := session.Contexts()
+		Ω(contexts).Should(HaveLen(1))
+		Ω(contexts[0].Name).Should(Equal("weather"))
+		Ω(contexts[0].Lifespan).Should(Equal(1))
+	})
+
+	It("Should keep caller contexts when the turn fails", func() {
+		server.AppendHandlers(
+			ghttp.RespondWith(http.StatusInternalServerError, ""),
+			ghttp.CombineHandlers(
+				ghttp.VerifyJSON(`{
+					"query": ["Hi"],
+					"contexts": [{"name": "music", "parameters": null, "lifespan": 1}],
+					"lang": "en",
+					"sessionId": "111"
+				}`),
+				ghttp.RespondWith(http.StatusOK, `{"result": {}, "status": {"code": 200}, "sessionId": "111"}`),
+			),
+		)
+		session.SetContext(DialogContext{Name: "music", Lifespan: 1})
+		_, err := session.Send("Hi")
+		Ω(err).Should(HaveOccurred())
+
+		_, err = session.Send("Hi")
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(session.Contexts()).Should(BeEmpty())
+	})
+
+	It("Should decrement lifespans of sent contexts", func() {
+		server.AppendHandlers(
+			ghttp.RespondWith(http.StatusOK, `{"result": {}, "status": {"code": 200}, "sessionId": "111"}`),
+		)
+		session.SetContext(DialogContext{Name: "music", Lifespan: 3})
+		_, err := session.Send("Hi")
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(session.Contexts()).Should(Equal([]DialogContext{{Name: "music", Lifespan: 2}}))
+	})
+
+	It("Should not block other methods during the request", func() {
+		release := make(chan struct{})
+		server.AppendHandlers(ghttp.CombineHandlers(
+			func(w http.ResponseWriter, r *http.Request) { <-release },
+			ghttp.RespondWith(http.StatusOK, `{"result": {"action": "greeting"}, "status": {"code": 200}, "sessionId": "111"}`),
+		))
+		done := make(chan error)
+		go func() {
+			_, err := session.Send("Hi")
+			done <- err
+		}()
+		Eventually(server.ReceivedRequests).Should(HaveLen(1))
+
+		session.Set("user", "Sam")
+		Ω(session.Contexts()).Should(BeEmpty())
+		Ω(session.LastAction()).Should(BeEmpty())
+
+		close(release)
+		Ω(<-done).ShouldNot(HaveOccurred())
+		Ω(session.LastAction()).Should(Equal("greeting"))
+	})
+})
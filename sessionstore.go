@@ -0,0 +1,346 @@
+package gapiai
+
+/***********************************************************************************************************************
+ *
+ * Go client-side library for API.AI
+ * =================================================
+ *
+ * Copyright (C) 2017 by Slava Vasylyev
+ *
+ *
+ * *********************************************************************************************************************
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance with
+ * the License. You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ *
+ ***********************************************************************************************************************/
+
+
+import (
+	"container/list"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+type (
+	//SessionState is the part of a Session kept in a SessionStore.
+	SessionState struct {
+		ID       string          `json:"id"`
+		Contexts []DialogContext `json:"contexts,omitempty"`
+		//Pending are contexts set by the caller for the next turn.
+		Pending    []DialogContext `json:"pending,omitempty"`
+		LastAction string          `json:"lastAction,omitempty"`
+		//Data is custom user data, it must be encodable to JSON.
+		Data      map[string]interface{} `json:"data,omitempty"`
+		UpdatedAt time.Time              `json:"updatedAt"`
+	}
+
+	//SessionStore keeps session state between turns, restarts and instances of the application.
+	SessionStore interface {
+		//Load returns ErrSessionNotFound when there is no state for the session.
+		Load(ctx context.Context, sessionID string) (*SessionState, error)
+		Save(ctx context.Context, state *SessionState) error
+		Delete(ctx context.Context, sessionID string) error
+	}
+
+	//KVClient is implemented by an adapter to an external key-value storage, e.g. Redis with
+	//GET, SET with expiration and DEL commands.
+	KVClient interface {
+		//Get returns ErrKeyNotFound when there is no value for the key.
+		Get(ctx context.Context, key string) ([]byte, error)
+		//Set stores the value for ttl, zero ttl means no expiration.
+		Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+		Del(ctx context.Context, key string) error
+	}
+
+	//MemorySessionStore keeps up to capacity most recently used sessions in memory for ttl since the
+	//last save. It is safe for concurrent use.
+	MemorySessionStore struct {
+		capacity int
+		ttl      time.Duration
+
+		mu  sync.Mutex
+		lru *list.List
+		//saved orders entries by the time of the last save, so expired ones are at its front.
+		saved   *list.List
+		entries map[string]*list.Element
+	}
+
+	memoryEntry struct {
+		id      string
+		data    []byte
+		expires time.Time
+		saved   *list.Element
+	}
+
+	//FileSessionStore keeps every session as a JSON file in a directory.
+	FileSessionStore struct {
+		dir string
+		ttl time.Duration
+	}
+
+	//KVSessionStore keeps sessions as JSON values of an external key-value storage.
+	KVSessionStore struct {
+		client KVClient
+		prefix string
+		ttl    time.Duration
+	}
+)
+
+var (
+	ErrSessionNotFound = errors.New("gapiai: session not found")
+	ErrKeyNotFound     = errors.New("gapiai: key not found")
+)
+
+//NewMemorySessionStore returns a store of up to capacity sessions, zero capacity or ttl mean no limit.
+//Expired sessions are removed on Save.
+func NewMemorySessionStore(capacity int, ttl time.Duration) *MemorySessionStore {
+	return &MemorySessionStore{
+		capacity: capacity,
+		ttl:      ttl,
+		lru:      list.New(),
+		saved:    list.New(),
+		entries:  map[string]*list.Element{},
+	}
+}
+
+//Load returns a copy of the stored state, so changes are not visible until the state is saved.
+func (store *MemorySessionStore) Load(ctx context.Context, sessionID string) (*SessionState, error) {
+	store.mu.Lock()
+	element, ok := store.entries[sessionID]
+	if !ok {
+		store.mu.Unlock()
+		return nil, ErrSessionNotFound
+	}
+	entry := element.Value.(*memoryEntry)
+	if !entry.expires.IsZero() && time.Now().After(entry.expires) {
+		store.remove(element)
+		store.mu.Unlock()
+		return nil, ErrSessionNotFound
+	}
+	store.lru.MoveToFront(element)
+	data := entry.data
+	store.mu.Unlock()
+
+	return decodeSessionState(data)
+}
+
+func (store *MemorySessionStore) Save(ctx context.Context, state *SessionState) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	var expires time.Time
+	if store.ttl > 0 {
+		expires = now.Add(store.ttl)
+	}
+
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	store.removeExpired(now)
+	if element, ok := store.entries[state.ID]; ok {
+		entry := element.Value.(*memoryEntry)
+		entry.data = data
+		entry.expires = expires
+		store.lru.MoveToFront(element)
+		store.saved.MoveToBack(entry.saved)
+		return nil
+	}
+	entry := &memoryEntry{
+		id:      state.ID,
+		data:    data,
+		expires: expires,
+	}
+	entry.saved = store.saved.PushBack(entry)
+	store.entries[state.ID] = store.lru.PushFront(entry)
+	if store.capacity > 0 && store.lru.Len() > store.capacity {
+		store.remove(store.lru.Back())
+	}
+	return nil
+}
+
+//removeExpired must be called with mu held.
+func (store *MemorySessionStore) removeExpired(now time.Time) {
+	for front := store.saved.Front(); front != nil; front = store.saved.Front() {
+		entry := front.Value.(*memoryEntry)
+		if entry.expires.IsZero() || !now.After(entry.expires) {
+			return
+		}
+		store.remove(store.entries[entry.id])
+	}
+}
+
+//Len returns the number of stored sessions, expired ones not removed yet included.
+func (store *MemorySessionStore) Len() int {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	return store.lru.Len()
+}
+
+func (store *MemorySessionStore) Delete(ctx context.Context, sessionID string) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	if element, ok := store.entries[sessionID]; ok {
+		store.remove(element)
+	}
+	return nil
+}
+
+//remove must be called with mu held.
+func (store *MemorySessionStore) remove(element *list.Element) {
+	entry := element.Value.(*memoryEntry)
+	store.lru.Remove(element)
+	store.saved.Remove(entry.saved)
+	delete(store.entries, entry.id)
+}
+
+//NewFileSessionStore creates dir if it does not exist. Sessions not saved for ttl are removed when
+//loaded or swept, zero ttl means no expiration.
+func NewFileSessionStore(dir string, ttl time.Duration) (*FileSessionStore, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	return &FileSessionStore{
+		dir: dir,
+		ttl: ttl,
+	}, nil
+}
+
+//path encodes the session ID, so any ID maps to a file inside dir.
+func (store *FileSessionStore) path(sessionID string) string {
+	return filepath.Join(store.dir, hex.EncodeToString([]byte(sessionID))+".json")
+}
+
+func (store *FileSessionStore) Load(ctx context.Context, sessionID string) (*SessionState, error) {
+	data, err := ioutil.ReadFile(store.path(sessionID))
+	if os.IsNotExist(err) {
+		return nil, ErrSessionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	state, err := decodeSessionState(data)
+	if err != nil {
+		return nil, err
+	}
+	if store.ttl > 0 && time.Since(state.UpdatedAt) > store.ttl {
+		if err := store.Delete(ctx, sessionID); err != nil {
+			return nil, err
+		}
+		return nil, ErrSessionNotFound
+	}
+	return state, nil
+}
+
+//Sweep removes files of sessions not saved for ttl. It should be called periodically, as sessions
+//which are never loaded again are not removed otherwise.
+func (store *FileSessionStore) Sweep(ctx context.Context) error {
+	if store.ttl <= 0 {
+		return nil
+	}
+	files, err := ioutil.ReadDir(store.dir)
+	if err != nil {
+		return err
+	}
+	for _, f := range files {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if f.IsDir() || filepath.Ext(f.Name()) != ".json" || time.Since(f.ModTime()) <= store.ttl {
+			continue
+		}
+		if err := os.Remove(filepath.Join(store.dir, f.Name())); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+//Save writes the state to a temporary file renamed over the session file, so readers never see
+//a partially written state.
+func (store *FileSessionStore) Save(ctx context.Context, state *SessionState) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempFile(store.dir, ".session-")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Rename(tmp.Name(), store.path(state.ID)); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return nil
+}
+
+func (store *FileSessionStore) Delete(ctx context.Context, sessionID string) error {
+	err := os.Remove(store.path(sessionID))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+//NewKVSessionStore keeps sessions under prefix+sessionID keys expiring after ttl, zero ttl means no expiration.
+func NewKVSessionStore(client KVClient, prefix string, ttl time.Duration) *KVSessionStore {
+	return &KVSessionStore{
+		client: client,
+		prefix: prefix,
+		ttl:    ttl,
+	}
+}
+
+func (store *KVSessionStore) Load(ctx context.Context, sessionID string) (*SessionState, error) {
+	data, err := store.client.Get(ctx, store.prefix+sessionID)
+	if errors.Is(err, ErrKeyNotFound) {
+		return nil, ErrSessionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return decodeSessionState(data)
+}
+
+func (store *KVSessionStore) Save(ctx context.Context, state *SessionState) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	return store.client.Set(ctx, store.prefix+state.ID, data, store.ttl)
+}
+
+func (store *KVSessionStore) Delete(ctx context.Context, sessionID string) error {
+	return store.client.Del(ctx, store.prefix+sessionID)
+}
+
+func decodeSessionState(data []byte) (*SessionState, error) {
+	state := &SessionState{}
+	if err := json.Unmarshal(data, state); err != nil {
+		return nil, err
+	}
+	return state, nil
+}
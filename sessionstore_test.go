@@ -0,0 +1,266 @@
+package gapiai_test
+
+/***********************************************************************************************************************
+ *
+ * Go client-side library for API.AI
+ * =================================================
+ *
+ * Copyright (C) 2017 by Slava Vasylyev
+ *
+ *
+ * *********************************************************************************************************************
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance with
+ * the License. You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ *
+ ***********************************************************************************************************************/
+
+
+import (
+	. "github.com/slavaVA/go-api.ai"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/ghttp"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+//mapKV is KVClient backed by a map, standing in for Redis.
+type mapKV struct {
+	mu     sync.Mutex
+	values map[string][]byte
+	ttls   map[string]time.Duration
+}
+
+func (kv *mapKV) Get(ctx context.Context, key string) ([]byte, error) {
+	kv.mu.Lock()
+	defer kv.mu.Unlock()
+	value, ok := kv.values[key]
+	if !ok {
+		return nil, ErrKeyNotFound
+	}
+	return value, nil
+}
+
+func (kv *mapKV) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	kv.mu.Lock()
+	defer kv.mu.Unlock()
+	kv.values[key] = value
+	kv.ttls[key] = ttl
+	return nil
+}
+
+func (kv *mapKV) Del(ctx context.Context, key string) error {
+	kv.mu.Lock()
+	defer kv.mu.Unlock()
+	delete(kv.values, key)
+	return nil
+}
+
+var _ = Describe("SessionStore", func() {
+	ctx := context.Background()
+	state := func(id string) *SessionState {
+		return &SessionState{
+			ID:         id,
+			Contexts:   []DialogContext{{Name: "weather", Lifespan: 2, Parameters: Parameters{"city": "Kiev"}}},
+			LastAction: "weather.get",
+			Data:       map[string]interface{}{"visits": 3.0},
+			UpdatedAt:  time.Now().Round(0),
+		}
+	}
+
+	itShouldStoreSessions := func(newStore func() SessionStore) {
+		It("Should save, load and delete session state", func() {
+			store := newStore()
+			_, err := store.Load(ctx, "1")
+			Ω(err).Should(Equal(ErrSessionNotFound))
+
+			Ω(store.Save(ctx, state("1"))).Should(Succeed())
+			loaded, err := store.Load(ctx, "1")
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(loaded.Contexts).Should(Equal(state("1").Contexts))
+			Ω(loaded.LastAction).Should(Equal("weather.get"))
+			Ω(loaded.Data).Should(Equal(map[string]interface{}{"visits": 3.0}))
+
+			Ω(store.Delete(ctx, "1")).Should(Succeed())
+			_, err = store.Load(ctx, "1")
+			Ω(err).Should(Equal(ErrSessionNotFound))
+			Ω(store.Delete(ctx, "1")).Should(Succeed())
+		})
+	}
+
+	Describe("Memory", func() {
+		itShouldStoreSessions(func() SessionStore { return NewMemorySessionStore(10, time.Minute) })
+
+		It("Should evict least recently used sessions", func() {
+			store := NewMemorySessionStore(2, 0)
+			Ω(store.Save(ctx, state("1"))).Should(Succeed())
+			Ω(store.Save(ctx, state("2"))).Should(Succeed())
+			_, err := store.Load(ctx, "1")
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(store.Save(ctx, state("3"))).Should(Succeed())
+
+			_, err = store.Load(ctx, "2")
+			Ω(err).Should(Equal(ErrSessionNotFound))
+			_, err = store.Load(ctx, "1")
+			Ω(err).ShouldNot(HaveOccurred())
+		})
+
+		It("Should expire sessions after ttl", func() {
+			store := NewMemorySessionStore(0, 10*time.Millisecond)
+			Ω(store.Save(ctx, state("1"))).Should(Succeed())
+			Eventually(func() error {
+				_, err := store.Load(ctx, "1")
+				return err
+			}).Should(Equal(ErrSessionNotFound))
+		})
+
+		It("Should remove expired sessions on save", func() {
+			store := NewMemorySessionStore(0, 10*time.Millisecond)
+			Ω(store.Save(ctx, state("1"))).Should(Succeed())
+			Ω(store.Save(ctx, state("2"))).Should(Succeed())
+			time.Sleep(20 * time.Millisecond)
+			Ω(store.Save(ctx, state("3"))).Should(Succeed())
+			Ω(store.Len()).Should(Equal(1))
+		})
+	})
+
+	Describe("File", func() {
+		var dir string
+		BeforeEach(func() {
+			var err error
+			dir, err = ioutil.TempDir("", "sessions")
+			Ω(err).ShouldNot(HaveOccurred())
+		})
+		AfterEach(func() {
+			os.RemoveAll(dir)
+		})
+
+		itShouldStoreSessions(func() SessionStore {
+			store, err := NewFileSessionStore(dir, 0)
+			Ω(err).ShouldNot(HaveOccurred())
+			return store
+		})
+
+		It("Should keep sessions in directory across store instances", func() {
+			store, err := NewFileSessionStore(dir, time.Hour)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(store.Save(ctx, state("../escape"))).Should(Succeed())
+
+			reopened, err := NewFileSessionStore(dir, time.Hour)
+			Ω(err).ShouldNot(HaveOccurred())
+			loaded, err := reopened.Load(ctx, "../escape")
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(loaded.ID).Should(Equal("../escape"))
+
+			files, _ := ioutil.ReadDir(dir)
+			Ω(files).Should(HaveLen(1))
+
+			expired := state("old")
+			expired.UpdatedAt = time.Now().Add(-2 * time.Hour)
+			Ω(store.Save(ctx, expired)).Should(Succeed())
+			_, err = store.Load(ctx, "old")
+			Ω(err).Should(Equal(ErrSessionNotFound))
+			files, _ = ioutil.ReadDir(dir)
+			Ω(files).Should(HaveLen(1))
+		})
+
+		It("Should sweep expired session files", func() {
+			store, err := NewFileSessionStore(dir, time.Hour)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(store.Save(ctx, state("1"))).Should(Succeed())
+			Ω(store.Save(ctx, state("2"))).Should(Succeed())
+			files, _ := ioutil.ReadDir(dir)
+			Ω(files).Should(HaveLen(2))
+			old := time.Now().Add(-2 * time.Hour)
+			Ω(os.Chtimes(filepath.Join(dir, files[0].Name()), old, old)).Should(Succeed())
+
+			Ω(store.Sweep(ctx)).Should(Succeed())
+			files, _ = ioutil.ReadDir(dir)
+			Ω(files).Should(HaveLen(1))
+		})
+	})
+
+	Describe("KV", func() {
+		var kv *mapKV
+		BeforeEach(func() {
+			kv = &mapKV{values: map[string][]byte{}, ttls: map[string]time.Duration{}}
+		})
+
+		itShouldStoreSessions(func() SessionStore { return NewKVSessionStore(kv, "apiai:", time.Hour) })
+
+		It("Should prefix keys and pass ttl", func() {
+			store := NewKVSessionStore(kv, "apiai:", time.Hour)
+			Ω(store.Save(ctx, state("1"))).Should(Succeed())
+			Ω(kv.values).Should(HaveKey("apiai:1"))
+			Ω(kv.ttls["apiai:1"]).Should(Equal(time.Hour))
+		})
+	})
+
+	Describe("Session", func() {
+		It("Should persist session state between instances", func() {
+			server := ghttp.NewServer()
+			defer server.Close()
+			server.AppendHandlers(
+				ghttp.RespondWith(http.StatusOK, `{
+					"result": {"action": "weather.get", "contexts": [{"name": "weather", "parameters": {}, "lifespan": 3}]},
+					"status": {"code": 200},
+					"sessionId": "111"
+				}`),
+				ghttp.CombineHandlers(
+					ghttp.VerifyJSON(`{
+						"query": ["Tomorrow?"],
+						"contexts": [{"name": "music", "parameters": null, "lifespan": 1}],
+						"lang": "en",
+						"sessionId": "111"
+					}`),
+					ghttp.RespondWith(http.StatusOK, `{"result": {"action": "weather.tomorrow"}, "status": {"code": 200}, "sessionId": "111"}`),
+				),
+			)
+			apiConfig := &ApiConfig{
+				AccessToken: "123456789",
+				Lang:        English,
+			}
+			apiService := NewQueryAPIEndpoint(server.URL()+"/v1/", CurrentAPIVersion, apiConfig)
+			store := NewMemorySessionStore(10, time.Hour)
+
+			session, err := LoadSession(ctx, apiService, store, "111")
+			Ω(err).ShouldNot(HaveOccurred())
+			session.Set("user", "Sam")
+			_, err = session.Send("Weather?")
+			Ω(err).ShouldNot(HaveOccurred())
+			session.SetContext(DialogContext{Name: "music", Lifespan: 1})
+			Ω(session.Save(ctx)).Should(Succeed())
+
+			restored, err := LoadSession(ctx, apiService, store, "111")
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(restored.LastAction()).Should(Equal("weather.get"))
+			user, ok := restored.Get("user")
+			Ω(ok).Should(BeTrue())
+			Ω(user).Should(Equal("Sam"))
+			Ω(restored.Contexts()).Should(HaveLen(1))
+
+			_, err = restored.Send("Tomorrow?")
+			Ω(err).ShouldNot(HaveOccurred())
+			saved, err := store.Load(ctx, "111")
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(saved.LastAction).Should(Equal("weather.tomorrow"))
+			Ω(saved.Pending).Should(BeEmpty())
+			Ω(saved.Contexts).Should(Equal([]DialogContext{
+				{Name: "weather", Parameters: Parameters{}, Lifespan: 2},
+			}))
+		})
+	})
+})
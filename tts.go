@@ -22,10 +22,10 @@ package gapiai
  ***********************************************************************************************************************/
 
 import (
-	"errors"
+	"context"
 	"fmt"
-	"net/http"
 	"io"
+	"net/http"
 	"os"
 )
 
@@ -52,6 +52,16 @@ func DefaultTtsAPIEndpoint(cfg *ApiConfig) *TtsService {
 }
 
 func (service *TtsService) DoTts(text string, handler SpeechHandler) error {
+	return service.DoTtsContext(context.Background(), text, handler)
+}
+
+//DoTtsContext generates speech bound to ctx. Cancellation or deadline of ctx aborts the request and
+//the context error is returned. UnsupportedCapabilityError is returned without a request when the
+//language of the config has no TTS.
+func (service *TtsService) DoTtsContext(ctx context.Context, text string, handler SpeechHandler) error {
+	if err := checkCapability(service.Config.Lang, TTS); err != nil {
+		return err
+	}
 
 	req, err := http.NewRequest("GET", service.url, nil)
 	if err != nil {
@@ -68,15 +78,18 @@ func (service *TtsService) DoTts(text string, handler SpeechHandler) error {
 	req.URL.RawQuery = query.Encode()
 	service.debug("Raw query", req.URL.RawQuery)
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	resp, err := service.send(ctx, req)
 	if err != nil {
 		return err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return errors.New("Http Status " + resp.Status)
+		body, err := readBody(ctx, resp)
+		if err != nil {
+			return err
+		}
+		return newAPIError(resp, body)
 	}
 	return handler(resp.Body)
 }
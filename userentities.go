@@ -0,0 +1,109 @@
+package gapiai
+
+/***********************************************************************************************************************
+ *
+ * Go client-side library for API.AI
+ * =================================================
+ *
+ * Copyright (C) 2017 by Slava Vasylyev
+ *
+ *
+ * *********************************************************************************************************************
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance with
+ * the License. You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ *
+ ***********************************************************************************************************************/
+
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+)
+
+type (
+	UserEntitiesService struct {
+		ApiService
+		url     string
+		version string
+	}
+)
+
+func NewUserEntitiesAPIEndpoint(url string, version string, cfg *ApiConfig) *UserEntitiesService {
+	svc := &UserEntitiesService{
+		ApiService: ApiService{
+			logger: nil,
+			Config: cfg,
+		},
+		url:     fmt.Sprint(url, "userEntities"),
+		version: version,
+	}
+	return svc
+}
+
+func DefaultUserEntitiesAPIEndpoint(cfg *ApiConfig) *UserEntitiesService {
+	return NewUserEntitiesAPIEndpoint(apiAiURL, CurrentAPIVersion, cfg)
+}
+
+func (service *UserEntitiesService) entitiesURL(sessionID string, path ...string) string {
+	var params url.Values
+	if sessionID != "" {
+		params = url.Values{"sessionId": {sessionID}}
+	}
+	return endpointURL(service.url, service.version, params, path...)
+}
+
+//Create adds user entities to the session. Entities with Extend set extend developer entities of the
+//same name, others replace them for the session.
+func (service *UserEntitiesService) Create(sessionID string, entities ...Entity) error {
+	return service.CreateContext(context.Background(), sessionID, entities...)
+}
+
+func (service *UserEntitiesService) CreateContext(ctx context.Context, sessionID string, entities ...Entity) error {
+	request := struct {
+		SessionID string   `json:"sessionId"`
+		Entities  []Entity `json:"entities"`
+	}{
+		SessionID: sessionID,
+		Entities:  entities,
+	}
+	return service.doJSON(ctx, "POST", service.entitiesURL(""), request, nil)
+}
+
+//Get returns the named user entity of the session.
+func (service *UserEntitiesService) Get(sessionID string, name string) (*Entity, error) {
+	return service.GetContext(context.Background(), sessionID, name)
+}
+
+func (service *UserEntitiesService) GetContext(ctx context.Context, sessionID string, name string) (*Entity, error) {
+	entity := &Entity{}
+	if err := service.doJSON(ctx, "GET", service.entitiesURL(sessionID, name), nil, entity); err != nil {
+		return nil, err
+	}
+	return entity, nil
+}
+
+//Update replaces the user entity of the session with the same name.
+func (service *UserEntitiesService) Update(sessionID string, entity Entity) error {
+	return service.UpdateContext(context.Background(), sessionID, entity)
+}
+
+func (service *UserEntitiesService) UpdateContext(ctx context.Context, sessionID string, entity Entity) error {
+	return service.doJSON(ctx, "PUT", service.entitiesURL(sessionID, entity.Name), entity, nil)
+}
+
+//Delete removes the named user entity from the session.
+func (service *UserEntitiesService) Delete(sessionID string, name string) error {
+	return service.DeleteContext(context.Background(), sessionID, name)
+}
+
+func (service *UserEntitiesService) DeleteContext(ctx context.Context, sessionID string, name string) error {
+	return service.doJSON(ctx, "DELETE", service.entitiesURL(sessionID, name), nil, nil)
+}
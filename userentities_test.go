@@ -0,0 +1,104 @@
+package gapiai_test
+
+/***********************************************************************************************************************
+ *
+ * Go client-side library for API.AI
+ * =================================================
+ *
+ * Copyright (C) 2017 by Slava Vasylyev
+ *
+ *
+ * *********************************************************************************************************************
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance with
+ * the License. You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ *
+ ***********************************************************************************************************************/
+
+
+import (
+	. "github.com/slavaVA/go-api.ai"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/ghttp"
+	"net/http"
+)
+
+var _ = Describe("User Entities", func() {
+	var server *ghttp.Server
+	var apiService *UserEntitiesService
+	success := `{"status": {"code": 200, "errorType": "success"}}`
+	playlist := Entity{
+		Name: "playlist",
+		Entries: []EntityEntry{
+			{Value: "Road trip", Synonyms: []string{"Road trip", "Driving"}},
+		},
+		Extend: true,
+	}
+	playlistJSON := `{
+		"name": "playlist",
+		"entries": [{"value": "Road trip", "synonyms": ["Road trip", "Driving"]}],
+		"extend": true,
+		"isEnum": false
+	}`
+
+	BeforeEach(func() {
+		server = ghttp.NewServer()
+		apiConfig := &ApiConfig{
+			AccessToken: "123456789",
+			Lang:        English,
+		}
+		apiService = NewUserEntitiesAPIEndpoint(server.URL()+"/v1/", CurrentAPIVersion, apiConfig)
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("Should create session entities", func() {
+		server.AppendHandlers(
+			ghttp.CombineHandlers(
+				ghttp.VerifyRequest("POST", "/v1/userEntities", "v=20150910"),
+				ghttp.VerifyJSON(`{"sessionId": "111", "entities": [`+playlistJSON+`]}`),
+				ghttp.RespondWith(http.StatusOK, success),
+			),
+		)
+		Ω(apiService.Create("111", playlist)).Should(Succeed())
+	})
+
+	It("Should retrieve session entity", func() {
+		server.AppendHandlers(
+			ghttp.CombineHandlers(
+				ghttp.VerifyRequest("GET", "/v1/userEntities/playlist", "sessionId=111&v=20150910"),
+				ghttp.RespondWith(http.StatusOK, playlistJSON),
+			),
+		)
+		entity, err := apiService.Get("111", "playlist")
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(*entity).Should(Equal(playlist))
+	})
+
+	It("Should update and delete session entity", func() {
+		server.AppendHandlers(
+			ghttp.CombineHandlers(
+				ghttp.VerifyRequest("PUT", "/v1/userEntities/playlist", "sessionId=111&v=20150910"),
+				ghttp.VerifyJSON(playlistJSON),
+				ghttp.RespondWith(http.StatusOK, success),
+			),
+			ghttp.CombineHandlers(
+				ghttp.VerifyRequest("DELETE", "/v1/userEntities/playlist", "sessionId=111&v=20150910"),
+				ghttp.RespondWith(http.StatusOK, success),
+			),
+		)
+		Ω(apiService.Update("111", playlist)).Should(Succeed())
+		Ω(apiService.Delete("111", "playlist")).Should(Succeed())
+		Ω(server.ReceivedRequests()).Should(HaveLen(2))
+	})
+})
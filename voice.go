@@ -0,0 +1,163 @@
+package gapiai
+
+/***********************************************************************************************************************
+ *
+ * Go client-side library for API.AI
+ * =================================================
+ *
+ * Copyright (C) 2017 by Slava Vasylyev
+ *
+ *
+ * *********************************************************************************************************************
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance with
+ * the License. You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ *
+ ***********************************************************************************************************************/
+
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+)
+
+const (
+	voiceSampleRate    = 16000
+	voiceChannels      = 1
+	voiceBitsPerSample = 16
+	wavePCMFormat      = 1
+)
+
+//ErrUnsupportedAudio is returned when voice data is not a 16kHz mono 16-bit PCM WAV file.
+var ErrUnsupportedAudio = errors.New("gapiai: voice data must be 16kHz mono 16-bit PCM WAV")
+
+func (service *QueryService) VoiceRequest(sessionID string, voice io.Reader) (*QueryResponse, error) {
+	return service.VoiceRequestContext(context.Background(), sessionID, voice)
+}
+
+func (service *QueryService) VoiceRequestContext(ctx context.Context, sessionID string, voice io.Reader) (*QueryResponse, error) {
+	q := Query{
+		SessionID: sessionID,
+	}
+	return service.DoVoiceQueryContext(ctx, q, voice)
+}
+
+func (service *QueryService) DoVoiceQuery(q Query, voice io.Reader) (*QueryResponse, error) {
+	return service.DoVoiceQueryContext(context.Background(), q, voice)
+}
+
+//DoVoiceQueryContext sends voice as a multipart request. The query is sent as the "request" part and
+//must not contain query text, the voice WAV file is sent as the "voiceData" part.
+func (service *QueryService) DoVoiceQueryContext(ctx context.Context, q Query, voice io.Reader) (*QueryResponse, error) {
+	if len(q.Query) > 0 {
+		return nil, errors.New("gapiai: voice query must not contain query text")
+	}
+
+	//voice is read into memory to be validated and replayed on retry
+	voiceData, err := ioutil.ReadAll(voice)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkVoiceWave(voiceData); err != nil {
+		return nil, err
+	}
+
+	if err := service.applyDefaults(&q); err != nil {
+		return nil, err
+	}
+	if err := checkCapability(SupportedLang(q.Lang), SpeechRecognition); err != nil {
+		return nil, err
+	}
+
+	jsonStr, err := json.Marshal(q)
+	if err != nil {
+		return nil, err
+	}
+
+	service.debug("API AI voice request Body:", string(jsonStr), "voice bytes:", len(voiceData))
+
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+
+	header := textproto.MIMEHeader{}
+	header.Set("Content-Disposition", `form-data; name="request"`)
+	header.Set("Content-Type", "application/json; charset=utf-8")
+	part, err := writer.CreatePart(header)
+	if err != nil {
+		return nil, err
+	}
+	part.Write(jsonStr)
+
+	header = textproto.MIMEHeader{}
+	header.Set("Content-Disposition", `form-data; name="voiceData"; filename="voice.wav"`)
+	header.Set("Content-Type", "audio/wav")
+	part, err = writer.CreatePart(header)
+	if err != nil {
+		return nil, err
+	}
+	part.Write(voiceData)
+
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("POST", service.queryURL, body)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Authorization", "Bearer "+service.Config.AccessToken)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+
+	return service.query(ctx, req)
+}
+
+//checkVoiceWave walks RIFF chunks up to the "fmt " one and verifies the audio format.
+func checkVoiceWave(data []byte) error {
+	if len(data) < 12 || string(data[0:4]) != "RIFF" || string(data[8:12]) != "WAVE" {
+		return fmt.Errorf("%w: not a RIFF WAVE file", ErrUnsupportedAudio)
+	}
+	//chunk sizes are 32-bit unsigned, so offsets are computed in int64 to not overflow on 32-bit platforms
+	length := int64(len(data))
+	for pos := int64(12); pos+8 <= length; {
+		id := string(data[pos : pos+4])
+		size := int64(binary.LittleEndian.Uint32(data[pos+4 : pos+8]))
+		if id != "fmt " {
+			if pos+8+size > length {
+				return fmt.Errorf("%w: chunk %q exceeds file size", ErrUnsupportedAudio, id)
+			}
+			pos += 8 + size + size%2
+			continue
+		}
+		if size < 16 || pos+8+16 > length {
+			return fmt.Errorf("%w: truncated fmt chunk", ErrUnsupportedAudio)
+		}
+		format := data[pos+8:]
+		audioFormat := binary.LittleEndian.Uint16(format[0:2])
+		channels := binary.LittleEndian.Uint16(format[2:4])
+		sampleRate := binary.LittleEndian.Uint32(format[4:8])
+		bitsPerSample := binary.LittleEndian.Uint16(format[14:16])
+		if audioFormat != wavePCMFormat || channels != voiceChannels ||
+			sampleRate != voiceSampleRate || bitsPerSample != voiceBitsPerSample {
+			return fmt.Errorf("%w: got format %d, %d channels, %dHz, %d bits",
+				ErrUnsupportedAudio, audioFormat, channels, sampleRate, bitsPerSample)
+		}
+		return nil
+	}
+	return fmt.Errorf("%w: fmt chunk not found", ErrUnsupportedAudio)
+}
@@ -0,0 +1,142 @@
+package gapiai_test
+
+/***********************************************************************************************************************
+ *
+ * Go client-side library for API.AI
+ * =================================================
+ *
+ * Copyright (C) 2017 by Slava Vasylyev
+ *
+ *
+ * *********************************************************************************************************************
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance with
+ * the License. You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ *
+ ***********************************************************************************************************************/
+
+
+import (
+	. "github.com/slavaVA/go-api.ai"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"github.com/onsi/gomega/ghttp"
+	"io/ioutil"
+	"net/http"
+)
+
+//makeWave returns PCM WAV file with the given format and samples bytes of silence.
+func makeWave(sampleRate uint32, channels uint16, samples int) []byte {
+	buf := &bytes.Buffer{}
+	le := binary.LittleEndian
+	buf.WriteString("RIFF")
+	binary.Write(buf, le, uint32(36+samples))
+	buf.WriteString("WAVE")
+	buf.WriteString("fmt ")
+	binary.Write(buf, le, uint32(16))
+	binary.Write(buf, le, uint16(1))
+	binary.Write(buf, le, channels)
+	binary.Write(buf, le, sampleRate)
+	binary.Write(buf, le, sampleRate*uint32(channels)*2)
+	binary.Write(buf, le, channels*2)
+	binary.Write(buf, le, uint16(16))
+	buf.WriteString("data")
+	binary.Write(buf, le, uint32(samples))
+	buf.Write(make([]byte, samples))
+	return buf.Bytes()
+}
+
+var _ = Describe("Voice Query", func() {
+	var server *ghttp.Server
+	var apiService *QueryService
+	testToken := "123456789"
+
+	BeforeEach(func() {
+		server = ghttp.NewServer()
+		apiConfig := &ApiConfig{
+			AccessToken: testToken,
+			Lang:        English,
+		}
+		apiService = NewQueryAPIEndpoint(server.URL()+"/v1/", CurrentAPIVersion, apiConfig)
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("Should send request and voice data as multipart form", func() {
+		wave := makeWave(16000, 1, 320)
+		server.AppendHandlers(
+			ghttp.CombineHandlers(
+				ghttp.VerifyRequest("POST", "/v1/query", "v=20150910"),
+				ghttp.VerifyHeader(http.Header{
+					"Authorization": []string{"Bearer " + testToken},
+				}),
+				func(w http.ResponseWriter, req *http.Request) {
+					reader, err := req.MultipartReader()
+					Ω(err).ShouldNot(HaveOccurred())
+
+					part, err := reader.NextPart()
+					Ω(err).ShouldNot(HaveOccurred())
+					Ω(part.FormName()).Should(Equal("request"))
+					Ω(part.Header.Get("Content-Type")).Should(HavePrefix("application/json"))
+					request, _ := ioutil.ReadAll(part)
+					Ω(request).Should(MatchJSON(`{"lang":"en","sessionId":"111","timezone":"Europe/Kiev"}`))
+
+					part, err = reader.NextPart()
+					Ω(err).ShouldNot(HaveOccurred())
+					Ω(part.FormName()).Should(Equal("voiceData"))
+					Ω(part.Header.Get("Content-Type")).Should(Equal("audio/wav"))
+					voice, _ := ioutil.ReadAll(part)
+					Ω(voice).Should(Equal(wave))
+				},
+				ghttp.RespondWith(http.StatusOK, `{
+					"result": {"source": "agent", "resolvedQuery": "Hello"},
+					"status": {"code": 200, "errorType": "success"},
+					"sessionId": "111"
+				}`),
+			),
+		)
+		q := Query{
+			SessionID: "111",
+			Timezone:  "Europe/Kiev",
+		}
+		response, err := apiService.DoVoiceQuery(q, bytes.NewReader(wave))
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(response.Result.ResolvedQuery).Should(Equal("Hello"))
+		Ω(server.ReceivedRequests()).Should(HaveLen(1))
+	})
+
+	It("Should reject unsupported audio format", func() {
+		_, err := apiService.VoiceRequest("111", bytes.NewReader(makeWave(44100, 2, 320)))
+		Ω(errors.Is(err, ErrUnsupportedAudio)).Should(BeTrue())
+
+		_, err = apiService.VoiceRequest("111", bytes.NewReader([]byte("not a wave")))
+		Ω(errors.Is(err, ErrUnsupportedAudio)).Should(BeTrue())
+		Ω(server.ReceivedRequests()).Should(HaveLen(0))
+	})
+
+	It("Should reject chunks larger than the file", func() {
+		wave := makeWave(16000, 1, 320)
+		for _, size := range []uint32{0x7FFFFFF8, 0xFFFFFFF8, 1000} {
+			crafted := append([]byte{}, wave[:12]...)
+			crafted = append(crafted, "LIST"...)
+			crafted = append(crafted, 0, 0, 0, 0)
+			binary.LittleEndian.PutUint32(crafted[16:20], size)
+			crafted = append(crafted, wave[12:]...)
+			_, err := apiService.VoiceRequest("111", bytes.NewReader(crafted))
+			Ω(errors.Is(err, ErrUnsupportedAudio)).Should(BeTrue())
+		}
+		Ω(server.ReceivedRequests()).Should(HaveLen(0))
+	})
+})
@@ -0,0 +1,138 @@
+package webhook
+
+/***********************************************************************************************************************
+ *
+ * Go client-side library for API.AI
+ * =================================================
+ *
+ * Copyright (C) 2017 by Slava Vasylyev
+ *
+ *
+ * *********************************************************************************************************************
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance with
+ * the License. You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ *
+ ***********************************************************************************************************************/
+
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"net/http"
+)
+
+type (
+	//Verifier authenticates webhook requests before they are decoded.
+	Verifier interface {
+		Verify(r *http.Request) error
+	}
+
+	VerifierFunc func(r *http.Request) error
+
+	//VerificationError rejects a request with the given HTTP status. Verifiers may return other errors,
+	//those reject the request with 401 Unauthorized.
+	VerificationError struct {
+		Status int
+		Reason string
+		//Challenge, when set, is sent in the WWW-Authenticate header.
+		Challenge string
+	}
+
+	basicAuth struct {
+		username [sha256.Size]byte
+		password [sha256.Size]byte
+	}
+
+	headerSecret struct {
+		name   string
+		secret [sha256.Size]byte
+	}
+)
+
+func (f VerifierFunc) Verify(r *http.Request) error {
+	return f(r)
+}
+
+func (e *VerificationError) Error() string {
+	return "webhook: " + e.Reason
+}
+
+//BasicAuth verifies basic authentication credentials set for the webhook in the agent.
+//Missing or wrong credentials are rejected with 401 Unauthorized.
+func BasicAuth(username string, password string) Verifier {
+	return &basicAuth{
+		username: sha256.Sum256([]byte(username)),
+		password: sha256.Sum256([]byte(password)),
+	}
+}
+
+func (v *basicAuth) Verify(r *http.Request) error {
+	username, password, ok := r.BasicAuth()
+	if !ok {
+		return &VerificationError{
+			Status:    http.StatusUnauthorized,
+			Reason:    "basic auth credentials missing",
+			Challenge: `Basic realm="webhook"`,
+		}
+	}
+	if !secureCompare(v.username, username) || !secureCompare(v.password, password) {
+		return &VerificationError{
+			Status:    http.StatusUnauthorized,
+			Reason:    "basic auth credentials mismatch",
+			Challenge: `Basic realm="webhook"`,
+		}
+	}
+	return nil
+}
+
+//HeaderSecret verifies a header set for the webhook in the agent. A missing header is rejected with
+//401 Unauthorized, a wrong value with 403 Forbidden.
+func HeaderSecret(name string, secret string) Verifier {
+	return &headerSecret{
+		name:   name,
+		secret: sha256.Sum256([]byte(secret)),
+	}
+}
+
+func (v *headerSecret) Verify(r *http.Request) error {
+	values, ok := r.Header[http.CanonicalHeaderKey(v.name)]
+	if !ok || len(values) == 0 {
+		return &VerificationError{
+			Status: http.StatusUnauthorized,
+			Reason: "header " + v.name + " missing",
+		}
+	}
+	if !secureCompare(v.secret, values[0]) {
+		return &VerificationError{
+			Status: http.StatusForbidden,
+			Reason: "header " + v.name + " mismatch",
+		}
+	}
+	return nil
+}
+
+//AllOf passes requests accepted by all verifiers, e.g. both basic auth and a header secret.
+func AllOf(verifiers ...Verifier) Verifier {
+	return VerifierFunc(func(r *http.Request) error {
+		for _, v := range verifiers {
+			if err := v.Verify(r); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+//secureCompare compares hashes, so neither the content nor the length of the expected value leaks
+//through timing.
+func secureCompare(expected [sha256.Size]byte, actual string) bool {
+	hash := sha256.Sum256([]byte(actual))
+	return subtle.ConstantTimeCompare(expected[:], hash[:]) == 1
+}
@@ -0,0 +1,108 @@
+package webhook_test
+
+/***********************************************************************************************************************
+ *
+ * Go client-side library for API.AI
+ * =================================================
+ *
+ * Copyright (C) 2017 by Slava Vasylyev
+ *
+ *
+ * *********************************************************************************************************************
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance with
+ * the License. You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ *
+ ***********************************************************************************************************************/
+
+
+import (
+	. "github.com/slavaVA/go-api.ai/webhook"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+)
+
+var _ = Describe("Verifier", func() {
+	var handler *Handler
+	var logs *bytes.Buffer
+
+	request := func(setup func(r *http.Request)) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		r := httptest.NewRequest("POST", "/webhook", strings.NewReader(requestJSON))
+		setup(r)
+		handler.ServeHTTP(recorder, r)
+		return recorder
+	}
+
+	BeforeEach(func() {
+		logs = &bytes.Buffer{}
+		handler = NewHandler()
+		handler.EnableLogger(logs)
+		handler.Fallback = func(ctx context.Context, req *Request) (*Response, error) {
+			return &Response{Speech: "ok"}, nil
+		}
+	})
+
+	It("Should verify basic auth", func() {
+		handler.Verifier = BasicAuth("apiai", "secret")
+
+		recorder := request(func(r *http.Request) {})
+		Ω(recorder.Code).Should(Equal(http.StatusUnauthorized))
+		Ω(recorder.Header().Get("WWW-Authenticate")).Should(Equal(`Basic realm="webhook"`))
+
+		recorder = request(func(r *http.Request) { r.SetBasicAuth("apiai", "wrong") })
+		Ω(recorder.Code).Should(Equal(http.StatusUnauthorized))
+		Ω(logs.String()).Should(ContainSubstring("Webhook request rejected"))
+		Ω(logs.String()).Should(ContainSubstring("basic auth credentials mismatch"))
+
+		recorder = request(func(r *http.Request) { r.SetBasicAuth("apiai", "secret") })
+		Ω(recorder.Code).Should(Equal(http.StatusOK))
+	})
+
+	It("Should verify header secret", func() {
+		handler.Verifier = HeaderSecret("X-Webhook-Secret", "s3cr3t")
+
+		Ω(request(func(r *http.Request) {}).Code).Should(Equal(http.StatusUnauthorized))
+		Ω(request(func(r *http.Request) {
+			r.Header.Set("X-Webhook-Secret", "s3cr3")
+		}).Code).Should(Equal(http.StatusForbidden))
+		Ω(request(func(r *http.Request) {
+			r.Header.Set("x-webhook-secret", "s3cr3t")
+		}).Code).Should(Equal(http.StatusOK))
+	})
+
+	It("Should combine verifiers and support custom ones", func() {
+		handler.Verifier = AllOf(
+			BasicAuth("apiai", "secret"),
+			HeaderSecret("X-Webhook-Secret", "s3cr3t"),
+		)
+		Ω(request(func(r *http.Request) {
+			r.SetBasicAuth("apiai", "secret")
+		}).Code).Should(Equal(http.StatusUnauthorized))
+		Ω(request(func(r *http.Request) {
+			r.SetBasicAuth("apiai", "secret")
+			r.Header.Set("X-Webhook-Secret", "s3cr3t")
+		}).Code).Should(Equal(http.StatusOK))
+
+		handler.Verifier = VerifierFunc(func(r *http.Request) error {
+			if r.Header.Get("X-Forwarded-For") == "" {
+				return errors.New("direct requests are not allowed")
+			}
+			return nil
+		})
+		Ω(request(func(r *http.Request) {}).Code).Should(Equal(http.StatusUnauthorized))
+	})
+})
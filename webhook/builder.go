@@ -0,0 +1,135 @@
+package webhook
+
+/***********************************************************************************************************************
+ *
+ * Go client-side library for API.AI
+ * =================================================
+ *
+ * Copyright (C) 2017 by Slava Vasylyev
+ *
+ *
+ * *********************************************************************************************************************
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance with
+ * the License. You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ *
+ ***********************************************************************************************************************/
+
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/slavaVA/go-api.ai"
+)
+
+type (
+	//ResponseBuilder builds Response with chained calls. Response is validated by Build.
+	ResponseBuilder struct {
+		resp Response
+	}
+)
+
+//ErrInvalidResponse is returned by Validate and Build for responses API.AI would reject or partially ignore.
+var ErrInvalidResponse = errors.New("webhook: invalid response")
+
+func NewResponseBuilder() *ResponseBuilder {
+	return &ResponseBuilder{}
+}
+
+func (b *ResponseBuilder) Speech(speech string) *ResponseBuilder {
+	b.resp.Speech = speech
+	return b
+}
+
+func (b *ResponseBuilder) DisplayText(text string) *ResponseBuilder {
+	b.resp.DisplayText = text
+	return b
+}
+
+func (b *ResponseBuilder) Source(source string) *ResponseBuilder {
+	b.resp.Source = source
+	return b
+}
+
+func (b *ResponseBuilder) Data(data interface{}) *ResponseBuilder {
+	b.resp.Data = data
+	return b
+}
+
+//Message appends rich messages.
+func (b *ResponseBuilder) Message(messages ...gapiai.Message) *ResponseBuilder {
+	b.resp.Messages = append(b.resp.Messages, messages...)
+	return b
+}
+
+//SetContext sets the output context, replacing a context with the same name set before.
+//Context names are case-insensitive, as in API.AI.
+func (b *ResponseBuilder) SetContext(name string, lifespan int, parameters gapiai.Parameters) *ResponseBuilder {
+	c := gapiai.DialogContext{
+		Name:       name,
+		Lifespan:   lifespan,
+		Parameters: parameters,
+	}
+	for i := range b.resp.ContextOut {
+		if strings.EqualFold(b.resp.ContextOut[i].Name, name) {
+			b.resp.ContextOut[i] = c
+			return b
+		}
+	}
+	b.resp.ContextOut = append(b.resp.ContextOut, c)
+	return b
+}
+
+//ClearContext removes the context from the session by setting its lifespan to 0.
+func (b *ResponseBuilder) ClearContext(name string) *ResponseBuilder {
+	return b.SetContext(name, 0, nil)
+}
+
+//Followup triggers the intent with the event. Speech, display text and messages must not be set then.
+func (b *ResponseBuilder) Followup(name string, data map[string]string) *ResponseBuilder {
+	b.resp.FollowupEvent = &gapiai.Event{
+		Name: name,
+		Data: data,
+	}
+	return b
+}
+
+//Build returns the validated response.
+func (b *ResponseBuilder) Build() (*Response, error) {
+	resp := b.resp
+	if err := resp.Validate(); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+//Validate checks that output contexts have names and non-negative lifespans, and that a followup
+//event is not combined with speech, display text or messages, which API.AI would ignore.
+func (resp *Response) Validate() error {
+	for _, c := range resp.ContextOut {
+		if c.Name == "" {
+			return fmt.Errorf("%w: output context without name", ErrInvalidResponse)
+		}
+		if c.Lifespan < 0 {
+			return fmt.Errorf("%w: output context %q has negative lifespan %d", ErrInvalidResponse, c.Name, c.Lifespan)
+		}
+	}
+	if resp.FollowupEvent != nil {
+		if resp.FollowupEvent.Name == "" {
+			return fmt.Errorf("%w: followup event without name", ErrInvalidResponse)
+		}
+		if resp.Speech != "" || resp.DisplayText != "" || len(resp.Messages) > 0 {
+			return fmt.Errorf("%w: followup event %q is combined with speech, display text or messages",
+				ErrInvalidResponse, resp.FollowupEvent.Name)
+		}
+	}
+	return nil
+}
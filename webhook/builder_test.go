@@ -0,0 +1,98 @@
+package webhook_test
+
+/***********************************************************************************************************************
+ *
+ * Go client-side library for API.AI
+ * =================================================
+ *
+ * Copyright (C) 2017 by Slava Vasylyev
+ *
+ *
+ * *********************************************************************************************************************
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance with
+ * the License. You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ *
+ ***********************************************************************************************************************/
+
+
+import (
+	"github.com/slavaVA/go-api.ai"
+	. "github.com/slavaVA/go-api.ai/webhook"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+)
+
+var _ = Describe("ResponseBuilder", func() {
+
+	It("Should build response with messages and contexts", func() {
+		resp, err := NewResponseBuilder().
+			Speech("It is sunny").
+			DisplayText("Sunny").
+			Source("weather-service").
+			Message(gapiai.NewTextMessage("It is sunny"), &gapiai.QuickRepliesMessage{Replies: []string{"Tomorrow?"}}).
+			SetContext("weather", 5, gapiai.Parameters{"city": "Kiev"}).
+			SetContext("Weather", 2, gapiai.Parameters{"city": "Lviv"}).
+			ClearContext("booking").
+			Build()
+		Ω(err).ShouldNot(HaveOccurred())
+
+		out, err := json.Marshal(resp)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(out).Should(MatchJSON(`{
+			"speech": "It is sunny",
+			"displayText": "Sunny",
+			"source": "weather-service",
+			"messages": [{"type": 0, "speech": "It is sunny"}, {"type": 2, "replies": ["Tomorrow?"]}],
+			"contextOut": [
+				{"name": "Weather", "parameters": {"city": "Lviv"}, "lifespan": 2},
+				{"name": "booking", "parameters": null, "lifespan": 0}
+			]
+		}`))
+	})
+
+	It("Should build followup event", func() {
+		resp, err := NewResponseBuilder().
+			Followup("WEATHER_UNKNOWN", map[string]string{"city": "Kiev"}).
+			SetContext("weather", 1, nil).
+			Build()
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(resp.FollowupEvent).Should(Equal(&gapiai.Event{Name: "WEATHER_UNKNOWN", Data: map[string]string{"city": "Kiev"}}))
+	})
+
+	It("Should reject invalid responses", func() {
+		_, err := NewResponseBuilder().Speech("Hi").Followup("WELCOME", nil).Build()
+		Ω(errors.Is(err, ErrInvalidResponse)).Should(BeTrue())
+
+		_, err = NewResponseBuilder().Followup("", nil).Build()
+		Ω(errors.Is(err, ErrInvalidResponse)).Should(BeTrue())
+
+		_, err = NewResponseBuilder().SetContext("weather", -1, nil).Build()
+		Ω(errors.Is(err, ErrInvalidResponse)).Should(BeTrue())
+
+		_, err = NewResponseBuilder().SetContext("", 1, nil).Build()
+		Ω(errors.Is(err, ErrInvalidResponse)).Should(BeTrue())
+	})
+
+	It("Should not send invalid handler response", func() {
+		handler := NewHandler()
+		handler.Fallback = func(ctx context.Context, req *Request) (*Response, error) {
+			return &Response{
+				Speech:        "Hi",
+				FollowupEvent: &gapiai.Event{Name: "WELCOME"},
+			}, nil
+		}
+		Ω(post(handler, requestJSON).Code).Should(Equal(http.StatusInternalServerError))
+	})
+})
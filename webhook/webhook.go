@@ -0,0 +1,227 @@
+package webhook
+
+/***********************************************************************************************************************
+ *
+ * Go client-side library for API.AI
+ * =================================================
+ *
+ * Copyright (C) 2017 by Slava Vasylyev
+ *
+ *
+ * *********************************************************************************************************************
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance with
+ * the License. You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ *
+ ***********************************************************************************************************************/
+
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/slavaVA/go-api.ai"
+)
+
+type (
+	//Request is sent by API.AI to the fulfillment webhook when an intent with webhook enabled is matched.
+	Request struct {
+		ID        string              `json:"id"`
+		Timestamp time.Time           `json:"timestamp"`
+		Lang      string              `json:"lang"`
+		Result    gapiai.QueryResult  `json:"result"`
+		Status    gapiai.StatusObject `json:"status"`
+		SessionID string              `json:"sessionId"`
+		//OriginalRequest is set when the query came from a one-click integration.
+		OriginalRequest *OriginalRequest `json:"originalRequest,omitempty"`
+	}
+
+	//OriginalRequest is the request received by a one-click integration, e.g. from Facebook or Google.
+	OriginalRequest struct {
+		Source string          `json:"source"`
+		Data   json.RawMessage `json:"data"`
+	}
+
+	//Response is returned by the webhook to API.AI.
+	Response struct {
+		Speech      string          `json:"speech,omitempty"`
+		DisplayText string          `json:"displayText,omitempty"`
+		Messages    gapiai.Messages `json:"messages,omitempty"`
+		//Data is passed as is to the integration, e.g. {"google": {...}}.
+		Data       interface{}            `json:"data,omitempty"`
+		ContextOut []gapiai.DialogContext `json:"contextOut,omitempty"`
+		//FollowupEvent triggers the intent with the event, speech and displayText are ignored then.
+		FollowupEvent *gapiai.Event `json:"followupEvent,omitempty"`
+		Source        string        `json:"source,omitempty"`
+	}
+
+	//HandlerFunc fulfills the request. Returned error or a response failing Validate make the webhook
+	//respond with 500 Internal Server Error, a nil response is sent as an empty one.
+	HandlerFunc func(ctx context.Context, req *Request) (*Response, error)
+
+	//Handler is an http.Handler routing webhook requests by result action or, if there is no handler for
+	//the action, by intent name.
+	Handler struct {
+		//Fallback is called when no handler matches the request. Requests are answered with
+		//404 Not Found when it is nil.
+		Fallback HandlerFunc
+		//Verifier, when set, authenticates every request before it is decoded.
+		Verifier Verifier
+		//MaxBodyBytes limits the request body, DefaultMaxBodyBytes is used when it is zero.
+		//Larger requests are answered with 413 Request Entity Too Large.
+		MaxBodyBytes int64
+
+		mu      sync.RWMutex
+		actions map[string]HandlerFunc
+		intents map[string]HandlerFunc
+		logger  *log.Logger
+	}
+)
+
+//DefaultMaxBodyBytes is the request body limit of handlers without MaxBodyBytes.
+const DefaultMaxBodyBytes = 1 << 20
+
+func NewHandler() *Handler {
+	return &Handler{
+		actions: map[string]HandlerFunc{},
+		intents: map[string]HandlerFunc{},
+	}
+}
+
+//HandleAction registers fn for requests with the result action.
+func (h *Handler) HandleAction(action string, fn HandlerFunc) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.actions[action] = fn
+}
+
+//HandleIntent registers fn for requests with the matched intent name.
+func (h *Handler) HandleIntent(intentName string, fn HandlerFunc) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.intents[intentName] = fn
+}
+
+func (h *Handler) EnableLogger(w io.Writer) {
+	h.logger = log.New(w,
+		"DEBUG: ",
+		log.Ldate|log.Ltime|log.Lshortfile)
+}
+
+func (h *Handler) debug(v ...interface{}) {
+	if h.logger != nil {
+		h.logger.Println(v...)
+	}
+}
+
+func (h *Handler) route(req *Request) HandlerFunc {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	if fn, ok := h.actions[req.Result.Action]; ok && req.Result.Action != "" {
+		return fn
+	}
+	if fn, ok := h.intents[req.Result.Metadata.IntentName]; ok && req.Result.Metadata.IntentName != "" {
+		return fn
+	}
+	return h.Fallback
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	if h.Verifier != nil {
+		if err := h.Verifier.Verify(r); err != nil {
+			h.reject(w, r, err)
+			return
+		}
+	}
+
+	maxBytes := h.MaxBodyBytes
+	if maxBytes <= 0 {
+		maxBytes = DefaultMaxBodyBytes
+	}
+	body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, maxBytes))
+	if err != nil {
+		h.debug("Webhook request read error:", err)
+		if int64(len(body)) >= maxBytes {
+			http.Error(w, http.StatusText(http.StatusRequestEntityTooLarge), http.StatusRequestEntityTooLarge)
+			return
+		}
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+
+	req := &Request{}
+	if err := json.Unmarshal(body, req); err != nil {
+		h.debug("Webhook request decode error:", err)
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+	h.debug("Webhook request: id", req.ID, "action", req.Result.Action, "intent", req.Result.Metadata.IntentName)
+
+	fn := h.route(req)
+	if fn == nil {
+		h.debug("Webhook has no handler for action", req.Result.Action, "intent", req.Result.Metadata.IntentName)
+		http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		return
+	}
+
+	resp, err := fn(r.Context(), req)
+	if err != nil {
+		h.debug("Webhook handler error:", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	if resp == nil {
+		resp = &Response{}
+	}
+	if err := resp.Validate(); err != nil {
+		h.debug("Webhook handler error:", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	body, err = json.Marshal(resp)
+	if err != nil {
+		h.debug("Webhook response encode error:", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	h.debug("Webhook response Body:", string(body))
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Write(body)
+}
+
+func (h *Handler) reject(w http.ResponseWriter, r *http.Request, err error) {
+	h.debug("Webhook request rejected:", r.RemoteAddr, err)
+
+	status := http.StatusUnauthorized
+	var verificationErr *VerificationError
+	if errors.As(err, &verificationErr) {
+		if verificationErr.Status != 0 {
+			status = verificationErr.Status
+		}
+		if verificationErr.Challenge != "" {
+			w.Header().Set("WWW-Authenticate", verificationErr.Challenge)
+		}
+	}
+	http.Error(w, http.StatusText(status), status)
+}
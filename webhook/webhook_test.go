@@ -0,0 +1,156 @@
+package webhook_test
+
+/***********************************************************************************************************************
+ *
+ * Go client-side library for API.AI
+ * =================================================
+ *
+ * Copyright (C) 2017 by Slava Vasylyev
+ *
+ *
+ * *********************************************************************************************************************
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance with
+ * the License. You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ *
+ ***********************************************************************************************************************/
+
+
+import (
+	"github.com/slavaVA/go-api.ai"
+	. "github.com/slavaVA/go-api.ai/webhook"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+)
+
+const requestJSON = `{
+  "id": "5bb49696-549d-4655-bfb1-21e1dc806379",
+  "timestamp": "2017-01-30T14:29:02.746Z",
+  "lang": "en",
+  "result": {
+    "source": "agent",
+    "resolvedQuery": "Weather in Kiev",
+    "action": "weather.get",
+    "actionIncomplete": false,
+    "parameters": {"city": "Kiev"},
+    "contexts": [{"name": "weather", "parameters": {"city": "Kiev"}, "lifespan": 5}],
+    "metadata": {
+      "intentId": "6500dd00-5f37-4fa0-a050-a8cf2428867b",
+      "webhookUsed": "true",
+      "webhookForSlotFillingUsed": "false",
+      "intentName": "Weather"
+    },
+    "fulfillment": {"speech": "", "messages": [{"type": 0, "speech": ""}]},
+    "score": 1
+  },
+  "status": {"code": 200, "errorType": "success"},
+  "sessionId": "111",
+  "originalRequest": {"source": "google", "data": {"user": {"userId": "u1"}}}
+}`
+
+func post(handler http.Handler, body string) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest("POST", "/webhook", strings.NewReader(body)))
+	return recorder
+}
+
+var _ = Describe("Handler", func() {
+	var handler *Handler
+
+	BeforeEach(func() {
+		handler = NewHandler()
+	})
+
+	It("Should decode request and route it by action", func() {
+		handler.HandleIntent("Weather", func(ctx context.Context, req *Request) (*Response, error) {
+			Fail("action handler must take precedence")
+			return nil, nil
+		})
+		handler.HandleAction("weather.get", func(ctx context.Context, req *Request) (*Response, error) {
+			Ω(req.SessionID).Should(Equal("111"))
+			Ω(req.Result.Parameters.GetString("city")).Should(Equal("Kiev"))
+			Ω(req.Result.Contexts[0].Lifespan).Should(Equal(5))
+			Ω(req.OriginalRequest.Source).Should(Equal("google"))
+			return &Response{
+				Speech:      "It is sunny in Kiev",
+				DisplayText: "Sunny",
+				Data:        map[string]interface{}{"google": map[string]interface{}{"expect_user_response": false}},
+				ContextOut:  []gapiai.DialogContext{{Name: "weather", Lifespan: 2}},
+				Source:      "weather-service",
+			}, nil
+		})
+
+		recorder := post(handler, requestJSON)
+		Ω(recorder.Code).Should(Equal(http.StatusOK))
+		Ω(recorder.Header().Get("Content-Type")).Should(Equal("application/json; charset=utf-8"))
+		Ω(recorder.Body.String()).Should(MatchJSON(`{
+			"speech": "It is sunny in Kiev",
+			"displayText": "Sunny",
+			"data": {"google": {"expect_user_response": false}},
+			"contextOut": [{"name": "weather", "parameters": null, "lifespan": 2}],
+			"source": "weather-service"
+		}`))
+	})
+
+	It("Should route by intent name and encode followup event", func() {
+		handler.HandleIntent("Weather", func(ctx context.Context, req *Request) (*Response, error) {
+			return &Response{
+				FollowupEvent: &gapiai.Event{Name: "WEATHER_UNKNOWN", Data: map[string]string{"city": "Kiev"}},
+			}, nil
+		})
+		recorder := post(handler, requestJSON)
+		Ω(recorder.Code).Should(Equal(http.StatusOK))
+		Ω(recorder.Body.String()).Should(MatchJSON(`{
+			"followupEvent": {"name": "WEATHER_UNKNOWN", "data": {"city": "Kiev"}}
+		}`))
+	})
+
+	It("Should use fallback and report unrouted requests", func() {
+		Ω(post(handler, requestJSON).Code).Should(Equal(http.StatusNotFound))
+
+		handler.Fallback = func(ctx context.Context, req *Request) (*Response, error) {
+			return nil, nil
+		}
+		recorder := post(handler, requestJSON)
+		Ω(recorder.Code).Should(Equal(http.StatusOK))
+		Ω(recorder.Body.String()).Should(MatchJSON(`{}`))
+	})
+
+	It("Should reject bad requests and report handler errors", func() {
+		handler.HandleAction("weather.get", func(ctx context.Context, req *Request) (*Response, error) {
+			return nil, errors.New("weather service is down")
+		})
+		Ω(post(handler, "{").Code).Should(Equal(http.StatusBadRequest))
+		Ω(post(handler, requestJSON).Code).Should(Equal(http.StatusInternalServerError))
+
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest("GET", "/webhook", nil))
+		Ω(recorder.Code).Should(Equal(http.StatusMethodNotAllowed))
+	})
+
+	It("Should reject requests larger than the body limit", func() {
+		handler.Fallback = func(ctx context.Context, req *Request) (*Response, error) {
+			return &Response{Speech: "Hello"}, nil
+		}
+		handler.MaxBodyBytes = int64(len(requestJSON))
+		Ω(post(handler, requestJSON).Code).Should(Equal(http.StatusOK))
+
+		handler.MaxBodyBytes = int64(len(requestJSON)) - 1
+		Ω(post(handler, requestJSON).Code).Should(Equal(http.StatusRequestEntityTooLarge))
+
+		handler.MaxBodyBytes = 0
+		Ω(post(handler, `{"id": "`+strings.Repeat("x", DefaultMaxBodyBytes)+`"}`).Code).Should(Equal(http.StatusRequestEntityTooLarge))
+	})
+})